@@ -7,149 +7,108 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
-	"fmt"
-	"io/ioutil"
+	"flag"
 	"log"
-	"os"
-	"path/filepath"
 
-	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
-	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/hyperledger/fabric-samples/test-application/go/apputil"
 )
 
 func main() {
-	log.Println("============ application-golang starts ============")
-
-	err := os.Setenv("DISCOVERY_AS_LOCALHOST", "true")
-	if err != nil {
-		log.Fatalf("Error setting DISCOVERY_AS_LOCALHOST environemnt variable: %v", err)
+	assetsFile := flag.String("assets", "", "CSV or JSON file of assets to create with CreateAssetsBatch")
+	transfersFile := flag.String("transfers", "", "CSV or JSON file of transfers to submit with TransferAssetsBatch")
+	batchSize := flag.Int("batch-size", 100, "maximum number of items per batch transaction")
+	clientFlags := apputil.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	if *batchSize <= 0 {
+		log.Fatalf("Invalid -batch-size %d: must be a positive integer", *batchSize)
 	}
 
-	wallet, err := gateway.NewFileSystemWallet("wallet")
-	if err != nil {
-		log.Fatalf("Failed to create wallet: %v", err)
-	}
+	log.Println("============ application-golang starts ============")
 
-	if !wallet.Exists("appUser") {
-		err = populateWallet(wallet)
-		if err != nil {
-			log.Fatalf("Failed to populate wallet contents: %v", err)
-		}
+	config, err := clientFlags.Config(apputil.DefaultConfig("basic"))
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 	}
 
-	ccpPath := filepath.Join(
-		"..",
-		"..",
-		"test-network",
-		"organizations",
-		"peerOrganizations",
-		"org1.example.com",
-		"connection-org1.yaml",
-	)
-
-	gw, err := gateway.Connect(
-		gateway.WithConfig(config.FromFile(filepath.Clean(ccpPath))),
-		gateway.WithIdentity(wallet, "appUser"),
-	)
+	client, err := apputil.NewClient(config)
 	if err != nil {
-		log.Fatalf("Failed to connect to gateway: %v", err)
+		log.Fatalf("Failed to create client: %v", err)
 	}
-	defer gw.Close()
+	defer client.Close()
 
-	network, err := gw.GetNetwork("mychannel")
+	contract, err := client.Contract()
 	if err != nil {
-		log.Fatalf("Failed to get network: %v", err)
+		log.Fatalf("Failed to get contract: %v", err)
 	}
 
-	contract := network.GetContract("basic")
-
 	log.Println("--> Submit Transaction: InitLedger, function creates the initial set of assets on the ledger")
-	result, err := contract.SubmitTransaction("InitLedger")
+	result, err := contract.Submit("InitLedger")
 	if err != nil {
 		log.Fatalf("Failed to Submit transaction: %v", err)
 	}
 	log.Println(string(result))
 
 	log.Println("--> Evaluate Transaction: GetAllAssets, function returns all the current assets on the ledger")
-	result, err = contract.EvaluateTransaction("GetAllAssets")
+	result, err = contract.Evaluate("GetAllAssets")
 	if err != nil {
 		log.Fatalf("Failed to evaluate transaction: %v", err)
 	}
 	log.Println(string(result))
 
 	log.Println("--> Submit Transaction: CreateAsset, creates new asset with ID, color, owner, size, and appraisedValue arguments")
-	result, err = contract.SubmitTransaction("CreateAsset", "asset13", "yellow", "5", "Tom", "1300")
+	result, err = contract.Submit("CreateAsset", "asset13", "yellow", "5", "Tom", "1300")
 	if err != nil {
 		log.Fatalf("Failed to Submit transaction: %v", err)
 	}
 	log.Println(string(result))
 
 	log.Println("--> Evaluate Transaction: ReadAsset, function returns an asset with a given assetID")
-	result, err = contract.EvaluateTransaction("ReadAsset", "asset13")
+	result, err = contract.Evaluate("ReadAsset", "asset13")
 	if err != nil {
 		log.Fatalf("Failed to evaluate transaction: %v\n", err)
 	}
 	log.Println(string(result))
 
 	log.Println("--> Evaluate Transaction: AssetExists, function returns 'true' if an asset with given assetID exist")
-	result, err = contract.EvaluateTransaction("AssetExists", "asset1")
+	result, err = contract.Evaluate("AssetExists", "asset1")
 	if err != nil {
 		log.Fatalf("Failed to evaluate transaction: %v\n", err)
 	}
 	log.Println(string(result))
 
-	log.Println("--> Submit Transaction: TransferAsset asset1, transfer to new owner of Tom")
-	_, err = contract.SubmitTransaction("TransferAsset", "asset1", "Tom")
+	log.Println("--> Submit Transaction: TransferAssetIfRevision asset1, transfer to new owner of Tom unless modified concurrently")
+	err = transferAssetWithRetry(contract, "asset1", "Tom", 3)
 	if err != nil {
 		log.Fatalf("Failed to Submit transaction: %v", err)
 	}
 
 	log.Println("--> Evaluate Transaction: ReadAsset, function returns 'asset1' attributes")
-	result, err = contract.EvaluateTransaction("ReadAsset", "asset1")
+	result, err = contract.Evaluate("ReadAsset", "asset1")
 	if err != nil {
 		log.Fatalf("Failed to evaluate transaction: %v", err)
 	}
 	log.Println(string(result))
-	log.Println("============ application-golang ends ============")
-}
 
-func populateWallet(wallet *gateway.Wallet) error {
-	log.Println("============ Populating wallet ============")
-	credPath := filepath.Join(
-		"..",
-		"..",
-		"test-network",
-		"organizations",
-		"peerOrganizations",
-		"org1.example.com",
-		"users",
-		"User1@org1.example.com",
-		"msp",
-	)
-
-	certPath := filepath.Join(credPath, "signcerts", "cert.pem")
-	// read the certificate pem
-	cert, err := ioutil.ReadFile(filepath.Clean(certPath))
-	if err != nil {
-		return err
+	if *assetsFile != "" {
+		assets, err := readAssetsFile(*assetsFile)
+		if err != nil {
+			log.Fatalf("Failed to read assets file: %v", err)
+		}
+		err = createAssetsInBatches(contract, assets, *batchSize)
+		if err != nil {
+			log.Fatalf("Failed to create assets: %v", err)
+		}
 	}
 
-	keyDir := filepath.Join(credPath, "keystore")
-	// there's a single file in this dir containing the private key
-	files, err := ioutil.ReadDir(keyDir)
-	if err != nil {
-		return err
-	}
-	if len(files) != 1 {
-		return fmt.Errorf("keystore folder should have contain one file")
-	}
-	keyPath := filepath.Join(keyDir, files[0].Name())
-	key, err := ioutil.ReadFile(filepath.Clean(keyPath))
-	if err != nil {
-		return err
+	if *transfersFile != "" {
+		transfers, err := readTransfersFile(*transfersFile)
+		if err != nil {
+			log.Fatalf("Failed to read transfers file: %v", err)
+		}
+		err = transferAssetsInBatches(contract, transfers, *batchSize)
+		if err != nil {
+			log.Fatalf("Failed to transfer assets: %v", err)
+		}
 	}
-
-	identity := gateway.NewX509Identity("Org1MSP", string(cert), string(key))
-
-	return wallet.Put("appUser", identity)
+	log.Println("============ application-golang ends ============")
 }
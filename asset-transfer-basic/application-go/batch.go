@@ -0,0 +1,186 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-samples/test-application/go/apputil"
+)
+
+// asset mirrors the Asset record of the asset-transfer-basic chaincode
+type asset struct {
+	ID             string `json:"ID"`
+	Color          string `json:"color"`
+	Size           int    `json:"size"`
+	Owner          string `json:"owner"`
+	AppraisedValue int    `json:"appraisedValue"`
+	Revision       int    `json:"revision,omitempty"`
+}
+
+// assetTransfer mirrors the AssetTransfer item of the asset-transfer-basic chaincode
+type assetTransfer struct {
+	ID       string `json:"ID"`
+	NewOwner string `json:"newOwner"`
+}
+
+// readAssetsFile reads assets from a JSON array or from a CSV file with the
+// columns ID,color,size,owner,appraisedValue and an optional header row.
+func readAssetsFile(path string) ([]asset, error) {
+	if !isCSV(path) {
+		var assets []asset
+		return assets, readJSONFile(path, &assets)
+	}
+
+	records, err := readCSVFile(path, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []asset
+	for i, record := range records {
+		size, err := strconv.Atoi(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid size: %v", path, i+1, err)
+		}
+		appraisedValue, err := strconv.Atoi(record[4])
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid appraised value: %v", path, i+1, err)
+		}
+		assets = append(assets, asset{
+			ID:             record[0],
+			Color:          record[1],
+			Size:           size,
+			Owner:          record[3],
+			AppraisedValue: appraisedValue,
+		})
+	}
+
+	return assets, nil
+}
+
+// readTransfersFile reads transfers from a JSON array or from a CSV file with the
+// columns ID,newOwner and an optional header row.
+func readTransfersFile(path string) ([]assetTransfer, error) {
+	if !isCSV(path) {
+		var transfers []assetTransfer
+		return transfers, readJSONFile(path, &transfers)
+	}
+
+	records, err := readCSVFile(path, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []assetTransfer
+	for _, record := range records {
+		transfers = append(transfers, assetTransfer{ID: record[0], NewOwner: record[1]})
+	}
+
+	return transfers, nil
+}
+
+// createAssetsInBatches submits CreateAssetsBatch transactions of at most batchSize assets each
+func createAssetsInBatches(contract *apputil.Contract, assets []asset, batchSize int) error {
+	for start := 0; start < len(assets); start += batchSize {
+		end := start + batchSize
+		if end > len(assets) {
+			end = len(assets)
+		}
+		err := submitBatch(contract, "CreateAssetsBatch", start, assets[start:end])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transferAssetsInBatches submits TransferAssetsBatch transactions of at most batchSize transfers each
+func transferAssetsInBatches(contract *apputil.Contract, transfers []assetTransfer, batchSize int) error {
+	for start := 0; start < len(transfers); start += batchSize {
+		end := start + batchSize
+		if end > len(transfers) {
+			end = len(transfers)
+		}
+		err := submitBatch(contract, "TransferAssetsBatch", start, transfers[start:end])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// submitBatch submits a single batch transaction. Item indexes in the per-item error
+// report returned by the chaincode are relative to the batch starting at offset.
+func submitBatch(contract *apputil.Contract, function string, offset int, batch interface{}) error {
+	batchJSON, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("--> Submit Transaction: %s, items starting at %d", function, offset)
+	_, err = contract.Submit(function, string(batchJSON))
+	if err != nil {
+		return fmt.Errorf("batch starting at item %d failed: %v", offset, err)
+	}
+
+	return nil
+}
+
+func isCSV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+func readJSONFile(path string, v interface{}) error {
+	content, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, v)
+}
+
+// readCSVFile returns the records of the CSV file, skipping a header row starting with "ID"
+func readCSVFile(path string, columns int) ([][]string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = columns
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 && strings.EqualFold(record[0], "ID") {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
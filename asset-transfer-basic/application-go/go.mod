@@ -2,4 +2,6 @@ module asset-transfer-basic
 
 go 1.14
 
-require github.com/hyperledger/fabric-sdk-go v1.0.0-rc1
+require github.com/hyperledger/fabric-samples/test-application/go v0.0.0
+
+replace github.com/hyperledger/fabric-samples/test-application/go => ../../test-application/go
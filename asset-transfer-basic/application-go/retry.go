@@ -0,0 +1,46 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-samples/test-application/go/apputil"
+)
+
+// staleRevisionErrorCode prefixes the error returned by the chaincode when a
+// conditional write expects a revision the asset is no longer at
+const staleRevisionErrorCode = "STALE_REVISION"
+
+// isStaleRevision returns true when the transaction was rejected because the asset
+// was modified by another client since it was read
+func isStaleRevision(err error) bool {
+	return err != nil && strings.Contains(err.Error(), staleRevisionErrorCode)
+}
+
+// transferAssetWithRetry reads the asset and transfers it with TransferAssetIfRevision,
+// reading it again and retrying up to attempts times when another client modified it in between.
+func transferAssetWithRetry(contract *apputil.Contract, id string, newOwner string, attempts int) error {
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var current asset
+		err := contract.EvaluateJSON(&current, "ReadAsset", id)
+		if err != nil {
+			return err
+		}
+
+		_, err = contract.Submit("TransferAssetIfRevision", id, newOwner, strconv.Itoa(current.Revision))
+		if !isStaleRevision(err) {
+			return err
+		}
+		log.Printf("Asset %s was modified concurrently, retrying transfer (attempt %d of %d)", id, attempt, attempts)
+	}
+
+	return fmt.Errorf("failed to transfer asset %s after %d attempts", id, attempts)
+}
@@ -8,17 +8,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 
-	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/chaincode/ccserver"
 )
 
-type serverConfig struct {
-	CCID    string
-	Address string
-}
-
 // SmartContract provides functions for managing an asset
 type SmartContract struct {
 	contractapi.Contract
@@ -208,11 +202,8 @@ func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface
 }
 
 func main() {
-	// See chaincode.env.example
-	config := serverConfig{
-		CCID:    os.Getenv("CHAINCODE_ID"),
-		Address: os.Getenv("CHAINCODE_SERVER_ADDRESS"),
-	}
+	// See chaincode.env
+	config := ccserver.LoadConfig()
 
 	chaincode, err := contractapi.NewChaincode(&SmartContract{})
 
@@ -220,16 +211,7 @@ func main() {
 		log.Panicf("error create asset-transfer-basic chaincode: %s", err)
 	}
 
-	server := &shim.ChaincodeServer{
-		CCID:    config.CCID,
-		Address: config.Address,
-		CC:      chaincode,
-		TLSProps: shim.TLSProperties{
-			Disabled: true,
-		},
-	}
-
-	if err := server.Start(); err != nil {
+	if err := ccserver.Run(config, chaincode); err != nil {
 		log.Panicf("error starting asset-transfer-basic chaincode: %s", err)
 	}
 }
@@ -0,0 +1,240 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// DefaultMaxBatchSize is the maximum number of items accepted by a batch function
+// when the contract configuration does not set its own limit.
+const DefaultMaxBatchSize = 100
+
+// AssetTransfer describes a single item of a TransferAssetsBatch call
+type AssetTransfer struct {
+	ID       string `json:"ID"`
+	NewOwner string `json:"newOwner"`
+}
+
+// BatchItemError reports why a single item of a batch was rejected
+type BatchItemError struct {
+	Index int    `json:"index"`
+	ID    string `json:"ID"`
+	Error string `json:"error"`
+}
+
+// BatchError is returned when one or more items of a batch are rejected.
+// No item of a rejected batch is written to the world state.
+type BatchError struct {
+	Items []BatchItemError `json:"items"`
+}
+
+// Error returns the per-item report as JSON so that clients can parse it from the transaction error
+func (e *BatchError) Error() string {
+	report, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Sprintf("batch rejected: %d invalid items", len(e.Items))
+	}
+
+	return fmt.Sprintf("batch rejected: %s", report)
+}
+
+// add records the rejection of the item at the given index
+func (e *BatchError) add(index int, id string, format string, args ...interface{}) {
+	e.Items = append(e.Items, BatchItemError{Index: index, ID: id, Error: fmt.Sprintf(format, args...)})
+}
+
+// CreateAssetsBatch issues every asset of the JSON array to the world state.
+// All assets are validated before any is written: if any of them is invalid or already
+// exists the transaction fails with a BatchError listing every rejected item.
+func (s *SmartContract) CreateAssetsBatch(ctx contractapi.TransactionContextInterface, assetsJSON string) error {
+	var assets []*Asset
+	err := json.Unmarshal([]byte(assetsJSON), &assets)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal assets batch: %v", err)
+	}
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = checkBatchSize(len(assets), config.maxCreateBatchSize())
+	if err != nil {
+		return err
+	}
+
+	guard, err := s.newOwnershipGuard(ctx)
+	if err != nil {
+		return err
+	}
+
+	batchErr := &BatchError{}
+	seen := make(map[string]bool)
+	for i, asset := range assets {
+		if asset == nil || strings.TrimSpace(asset.ID) == "" {
+			batchErr.add(i, "", "asset ID must not be empty")
+			continue
+		}
+		err = guard.checkOwner(asset.Owner)
+		if err != nil {
+			batchErr.add(i, asset.ID, "%v", err)
+			continue
+		}
+		if seen[asset.ID] {
+			batchErr.add(i, asset.ID, "the asset %s appears more than once in the batch", asset.ID)
+			continue
+		}
+		seen[asset.ID] = true
+
+		exists, err := s.AssetExists(ctx, asset.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			batchErr.add(i, asset.ID, "the asset %s already exists", asset.ID)
+		}
+	}
+	if len(batchErr.Items) > 0 {
+		return batchErr
+	}
+
+	for _, asset := range assets {
+		asset.Revision = 1
+		asset.Delegates = nil
+		err := putAsset(ctx, asset)
+		if err != nil {
+			return err
+		}
+	}
+
+	return emitAssetBatchEvent(ctx, AssetsBatchCreatedEvent, make([]*Asset, len(assets)), assets)
+}
+
+// TransferAssetsBatch updates the owner of every asset listed in the JSON array of AssetTransfer.
+// All transfers are validated before any is written: if any asset does not exist or is listed
+// more than once the transaction fails with a BatchError listing every rejected item.
+func (s *SmartContract) TransferAssetsBatch(ctx contractapi.TransactionContextInterface, transfersJSON string) error {
+	var transfers []*AssetTransfer
+	err := json.Unmarshal([]byte(transfersJSON), &transfers)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal transfers batch: %v", err)
+	}
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = checkBatchSize(len(transfers), config.maxTransferBatchSize())
+	if err != nil {
+		return err
+	}
+
+	guard, err := s.newOwnershipGuard(ctx)
+	if err != nil {
+		return err
+	}
+
+	batchErr := &BatchError{}
+	seen := make(map[string]bool)
+	befores := make([]*Asset, len(transfers))
+	for i, transfer := range transfers {
+		if transfer == nil || strings.TrimSpace(transfer.ID) == "" {
+			batchErr.add(i, "", "asset ID must not be empty")
+			continue
+		}
+		if strings.TrimSpace(transfer.NewOwner) == "" {
+			batchErr.add(i, transfer.ID, "new owner must not be empty")
+			continue
+		}
+		if seen[transfer.ID] {
+			batchErr.add(i, transfer.ID, "the asset %s appears more than once in the batch", transfer.ID)
+			continue
+		}
+		seen[transfer.ID] = true
+
+		assetJSON, err := ctx.GetStub().GetState(transfer.ID)
+		if err != nil {
+			return fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if assetJSON == nil {
+			batchErr.add(i, transfer.ID, "the asset %s does not exist", transfer.ID)
+			continue
+		}
+
+		befores[i], _, err = unmarshalAsset(assetJSON)
+		if err != nil {
+			return err
+		}
+		err = guard.checkModify(befores[i])
+		if err == nil {
+			err = guard.checkNewOwner(transfer.NewOwner)
+		}
+		if err != nil {
+			batchErr.add(i, transfer.ID, "%v", err)
+		}
+	}
+	if len(batchErr.Items) > 0 {
+		return batchErr
+	}
+
+	afters := make([]*Asset, len(transfers))
+	for i, transfer := range transfers {
+		asset := *befores[i]
+		asset.Owner = transfer.NewOwner
+		asset.Delegates = nil
+		asset.Revision++
+		err := putAsset(ctx, &asset)
+		if err != nil {
+			return err
+		}
+		afters[i] = &asset
+	}
+
+	return emitAssetBatchEvent(ctx, AssetsBatchTransferredEvent, befores, afters)
+}
+
+// SetMaxBatchSizes changes the limits of CreateAssetsBatch and TransferAssetsBatch in the contract
+// configuration, so that every endorsing peer applies the same limits. A limit of 0 restores
+// DefaultMaxBatchSize. Only a client of the admin MSP can change the limits, once the contract
+// is initialized.
+func (s *SmartContract) SetMaxBatchSizes(ctx contractapi.TransactionContextInterface, maxCreateBatchSize int, maxTransferBatchSize int) error {
+	if maxCreateBatchSize < 0 || maxTransferBatchSize < 0 {
+		return fmt.Errorf("batch size limits must not be negative")
+	}
+
+	return s.updateConfig(ctx, func(config *ContractConfig) error {
+		config.MaxCreateBatchSize = maxCreateBatchSize
+		config.MaxTransferBatchSize = maxTransferBatchSize
+		return nil
+	})
+}
+
+// maxCreateBatchSize returns the configured limit for CreateAssetsBatch
+func (c *ContractConfig) maxCreateBatchSize() int {
+	if c.MaxCreateBatchSize > 0 {
+		return c.MaxCreateBatchSize
+	}
+
+	return DefaultMaxBatchSize
+}
+
+// maxTransferBatchSize returns the configured limit for TransferAssetsBatch
+func (c *ContractConfig) maxTransferBatchSize() int {
+	if c.MaxTransferBatchSize > 0 {
+		return c.MaxTransferBatchSize
+	}
+
+	return DefaultMaxBatchSize
+}
+
+// checkBatchSize rejects empty batches and batches larger than the limit
+func checkBatchSize(size int, limit int) error {
+	if size == 0 {
+		return fmt.Errorf("batch must contain at least one item")
+	}
+	if size > limit {
+		return fmt.Errorf("batch of %d items exceeds the maximum batch size of %d", size, limit)
+	}
+
+	return nil
+}
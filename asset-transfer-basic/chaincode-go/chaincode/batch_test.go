@@ -0,0 +1,162 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// requireBatchError asserts that err is a BatchError with the given per-item report
+func requireBatchError(t *testing.T, err error, expected ...chaincode.BatchItemError) {
+	batchErr, ok := err.(*chaincode.BatchError)
+	require.True(t, ok, "expected a BatchError, got %v", err)
+	require.Equal(t, expected, batchErr.Items)
+	require.True(t, strings.HasPrefix(err.Error(), "batch rejected: "))
+}
+
+func TestCreateAssetsBatch(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
+
+	assets := []*chaincode.Asset{
+		{ID: "asset1", Color: "blue", Size: 5, Owner: "Tomoko", AppraisedValue: 300},
+		{ID: "asset2", Color: "red", Size: 5, Owner: "Brad", AppraisedValue: 400},
+	}
+	assetsJSON, err := json.Marshal(assets)
+	require.NoError(t, err)
+
+	assetTransfer := chaincode.SmartContract{}
+	err = assetTransfer.CreateAssetsBatch(transactionContext, string(assetsJSON))
+	require.NoError(t, err)
+	require.Equal(t, 2, chaincodeStub.PutStateCallCount())
+	key, value := chaincodeStub.PutStateArgsForCall(1)
+	require.Equal(t, "asset2", key)
+	require.JSONEq(t, fmt.Sprintf(`{"schemaVersion":%d,"ID":"asset2","color":"red","size":5,"owner":"Brad","appraisedValue":400,"revision":1}`, chaincode.AssetSchemaVersion), string(value))
+
+	require.Equal(t, 1, chaincodeStub.SetEventCallCount())
+	name, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, chaincode.AssetsBatchCreatedEvent, name)
+	var event chaincode.AssetBatchEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Equal(t, chaincode.AssetEventVersion, event.Version)
+	require.Equal(t, clientID, event.ClientID)
+	require.Len(t, event.Events, 2)
+	require.Equal(t, "asset1", event.Events[0].AssetID)
+	require.Nil(t, event.Events[0].Before)
+	require.Equal(t, &chaincode.Asset{ID: "asset2", Color: "red", Size: 5, Owner: "Brad", AppraisedValue: 400, Revision: 1}, event.Events[1].After)
+
+	worldState := map[string][]byte{"asset4": {}}
+	chaincodeStub.GetStateStub = func(key string) ([]byte, error) {
+		return worldState[key], nil
+	}
+	err = assetTransfer.CreateAssetsBatch(transactionContext, `[{"ID":"asset3"},{"ID":""},{"ID":"asset3"},{"ID":"asset4"}]`)
+	requireBatchError(t, err,
+		chaincode.BatchItemError{Index: 1, Error: "asset ID must not be empty"},
+		chaincode.BatchItemError{Index: 2, ID: "asset3", Error: "the asset asset3 appears more than once in the batch"},
+		chaincode.BatchItemError{Index: 3, ID: "asset4", Error: "the asset asset4 already exists"},
+	)
+	require.Equal(t, 2, chaincodeStub.PutStateCallCount())
+
+	err = assetTransfer.CreateAssetsBatch(transactionContext, "not json")
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "failed to unmarshal assets batch: "))
+
+	err = assetTransfer.CreateAssetsBatch(transactionContext, "[]")
+	require.EqualError(t, err, "batch must contain at least one item")
+
+	chaincodeStub.CreateCompositeKeyReturns("\x00config\x00", nil)
+	worldState["\x00config\x00"] = []byte(`{"maxCreateBatchSize":1}`)
+	err = assetTransfer.CreateAssetsBatch(transactionContext, string(assetsJSON))
+	require.EqualError(t, err, "batch of 2 items exceeds the maximum batch size of 1")
+
+	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
+	err = assetTransfer.CreateAssetsBatch(transactionContext, string(assetsJSON))
+	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
+}
+
+func TestTransferAssetsBatch(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
+
+	asset1 := &chaincode.Asset{ID: "asset1", Owner: "Tomoko", Revision: 1}
+	bytes1, err := json.Marshal(asset1)
+	require.NoError(t, err)
+	asset2 := &chaincode.Asset{ID: "asset2", Owner: "Brad"}
+	bytes2, err := json.Marshal(asset2)
+	require.NoError(t, err)
+
+	worldState := map[string][]byte{"asset1": bytes1, "asset2": bytes2}
+	chaincodeStub.GetStateStub = func(key string) ([]byte, error) {
+		return worldState[key], nil
+	}
+	assetTransfer := chaincode.SmartContract{}
+	err = assetTransfer.TransferAssetsBatch(transactionContext, `[{"ID":"asset1","newOwner":"Max"},{"ID":"asset2","newOwner":"Max"}]`)
+	require.NoError(t, err)
+	require.Equal(t, 2, chaincodeStub.PutStateCallCount())
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "asset1", key)
+	require.JSONEq(t, fmt.Sprintf(`{"schemaVersion":%d,"ID":"asset1","color":"","size":0,"owner":"Max","appraisedValue":0,"revision":2}`, chaincode.AssetSchemaVersion), string(value))
+
+	require.Equal(t, 1, chaincodeStub.SetEventCallCount())
+	name, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, chaincode.AssetsBatchTransferredEvent, name)
+	var event chaincode.AssetBatchEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Len(t, event.Events, 2)
+	require.Equal(t, asset2, event.Events[1].Before)
+	require.Equal(t, &chaincode.Asset{ID: "asset2", Owner: "Max", Revision: 1}, event.Events[1].After)
+
+	err = assetTransfer.TransferAssetsBatch(transactionContext,
+		`[{"ID":"asset1","newOwner":"Max"},{"ID":"asset5","newOwner":"Max"},{"ID":"asset1","newOwner":"Jin Soo"},{"ID":"asset2"},{"newOwner":"Max"}]`)
+	requireBatchError(t, err,
+		chaincode.BatchItemError{Index: 1, ID: "asset5", Error: "the asset asset5 does not exist"},
+		chaincode.BatchItemError{Index: 2, ID: "asset1", Error: "the asset asset1 appears more than once in the batch"},
+		chaincode.BatchItemError{Index: 3, ID: "asset2", Error: "new owner must not be empty"},
+		chaincode.BatchItemError{Index: 4, Error: "asset ID must not be empty"},
+	)
+	require.Equal(t, 2, chaincodeStub.PutStateCallCount())
+
+	chaincodeStub.CreateCompositeKeyReturns("\x00config\x00", nil)
+	worldState["\x00config\x00"] = []byte(`{"maxTransferBatchSize":1}`)
+	err = assetTransfer.TransferAssetsBatch(transactionContext, `[{"ID":"asset1","newOwner":"Max"},{"ID":"asset2","newOwner":"Max"}]`)
+	require.EqualError(t, err, "batch of 2 items exceeds the maximum batch size of 1")
+
+	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
+	err = assetTransfer.TransferAssetsBatch(transactionContext, `[{"ID":"asset1","newOwner":"Max"}]`)
+	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
+}
+
+func TestSetMaxBatchSizes(t *testing.T) {
+	assetTransfer := chaincode.SmartContract{}
+
+	transactionContext, chaincodeStub := prepOwnershipMocks(t, true, ownerID, chaincode.DefaultAdminMSPID)
+	err := assetTransfer.SetMaxBatchSizes(transactionContext, 10, 20)
+	require.NoError(t, err)
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "\x00config\x00", key)
+	require.JSONEq(t, `{"enforceOwnership":true,"ownerMSPIDs":["Org2MSP"],"maxCreateBatchSize":10,"maxTransferBatchSize":20}`, string(value))
+
+	err = assetTransfer.SetMaxBatchSizes(transactionContext, -1, 20)
+	require.EqualError(t, err, "batch size limits must not be negative")
+
+	chaincodeStub.GetStateReturns(nil, nil)
+	err = assetTransfer.SetMaxBatchSizes(transactionContext, 10, 20)
+	require.EqualError(t, err, "the contract must be initialized first")
+
+	transactionContext, _ = prepOwnershipMocks(t, true, strangeID, "Org2MSP")
+	err = assetTransfer.SetMaxBatchSizes(transactionContext, 10, 20)
+	require.EqualError(t, err, "client of MSP Org2MSP is not authorized to run admin functions")
+}
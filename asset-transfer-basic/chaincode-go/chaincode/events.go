@@ -0,0 +1,104 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Names of the chaincode events emitted by the mutating asset functions
+const (
+	AssetCreatedEvent     = "AssetCreated"
+	AssetUpdatedEvent     = "AssetUpdated"
+	AssetDeletedEvent     = "AssetDeleted"
+	AssetTransferredEvent = "AssetTransferred"
+
+	AssetsBatchCreatedEvent     = "AssetsBatchCreated"
+	AssetsBatchTransferredEvent = "AssetsBatchTransferred"
+)
+
+// AssetEventVersion is the version of the AssetEvent payload layout.
+// It is incremented whenever the payload changes in a way listeners need to know about.
+const AssetEventVersion = 1
+
+// AssetEvent is the JSON payload of every asset chaincode event.
+// Before is nil for AssetCreated and After is nil for AssetDeleted.
+type AssetEvent struct {
+	Version  int    `json:"version"`
+	AssetID  string `json:"assetID"`
+	Before   *Asset `json:"before,omitempty"`
+	After    *Asset `json:"after,omitempty"`
+	ClientID string `json:"clientID"`
+}
+
+// AssetBatchEvent is the JSON payload of the events emitted by the batch functions.
+// Events holds one entry per asset changed by the batch, in batch order.
+type AssetBatchEvent struct {
+	Version  int          `json:"version"`
+	Events   []AssetEvent `json:"events"`
+	ClientID string       `json:"clientID"`
+}
+
+// emitAssetEvent sets the chaincode event for the current transaction.
+// A transaction carries a single chaincode event, so it must be called at most once per transaction.
+func emitAssetEvent(ctx contractapi.TransactionContextInterface, eventName string, before *Asset, after *Asset) error {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	return setEvent(ctx, eventName, newAssetEvent(clientID, before, after))
+}
+
+// emitAssetBatchEvent sets a single chaincode event for the current transaction
+// holding one AssetEvent per asset changed by a batch function.
+func emitAssetBatchEvent(ctx contractapi.TransactionContextInterface, eventName string, befores []*Asset, afters []*Asset) error {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	event := AssetBatchEvent{
+		Version:  AssetEventVersion,
+		ClientID: clientID,
+	}
+	for i := range afters {
+		event.Events = append(event.Events, newAssetEvent(clientID, befores[i], afters[i]))
+	}
+
+	return setEvent(ctx, eventName, event)
+}
+
+// newAssetEvent builds the event payload describing a single asset change
+func newAssetEvent(clientID string, before *Asset, after *Asset) AssetEvent {
+	assetID := ""
+	if after != nil {
+		assetID = after.ID
+	} else if before != nil {
+		assetID = before.ID
+	}
+
+	return AssetEvent{
+		Version:  AssetEventVersion,
+		AssetID:  assetID,
+		Before:   before,
+		After:    after,
+		ClientID: clientID,
+	}
+}
+
+// setEvent marshals the payload and sets it as the chaincode event of the current transaction
+func setEvent(ctx contractapi.TransactionContextInterface, eventName string, payload interface{}) error {
+	eventJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().SetEvent(eventName, eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set %s event: %v", eventName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// HistoryQueryResult structure used for returning result of history query
+type HistoryQueryResult struct {
+	Record    *Asset    `json:"record"`
+	TxId      string    `json:"txId"`
+	Timestamp time.Time `json:"timestamp"`
+	IsDelete  bool      `json:"isDelete"`
+}
+
+// GetAssetHistory returns the chain of custody for an asset since issuance.
+// Requires the peer to have the history database enabled.
+func (s *SmartContract) GetAssetHistory(ctx contractapi.TransactionContextInterface, id string) ([]HistoryQueryResult, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var records []HistoryQueryResult
+	for resultsIterator.HasNext() {
+		response, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		asset := &Asset{
+			ID: id,
+		}
+		if len(response.Value) > 0 {
+			asset, _, err = unmarshalAsset(response.Value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		timestamp, err := ptypes.Timestamp(response.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		record := HistoryQueryResult{
+			TxId:      response.TxId,
+			Timestamp: timestamp,
+			Record:    asset,
+			IsDelete:  response.IsDelete,
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// ReadAssetAsOf returns the asset as it was at the given RFC 3339 timestamp, along with the
+// ID of the transaction that last wrote it. If the asset had been deleted at that time,
+// IsDelete is set on the result and the record only carries the asset ID.
+func (s *SmartContract) ReadAssetAsOf(ctx contractapi.TransactionContextInterface, id string, timestamp string) (*HistoryQueryResult, error) {
+	asOf, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %s, expected RFC 3339 format: %v", timestamp, err)
+	}
+
+	history, err := s.GetAssetHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the history is not guaranteed to be ordered, so pick the latest modification at or before asOf
+	var result *HistoryQueryResult
+	for i := range history {
+		if history[i].Timestamp.After(asOf) {
+			continue
+		}
+		if result == nil || history[i].Timestamp.After(result.Timestamp) {
+			result = &history[i]
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("the asset %s did not exist at %s", id, timestamp)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAssetHistory(t *testing.T) {
+	asset := &chaincode.Asset{ID: "asset1", Owner: "Tomoko"}
+	bytes, err := json.Marshal(asset)
+	require.NoError(t, err)
+
+	iterator := &mocks.HistoryQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, true)
+	iterator.HasNextReturnsOnCall(2, false)
+	iterator.NextReturnsOnCall(0, &queryresult.KeyModification{TxId: "tx2", Timestamp: &timestamp.Timestamp{Seconds: 200}, IsDelete: true}, nil)
+	iterator.NextReturnsOnCall(1, &queryresult.KeyModification{TxId: "tx1", Value: bytes, Timestamp: &timestamp.Timestamp{Seconds: 100}}, nil)
+
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+
+	chaincodeStub.GetHistoryForKeyReturns(iterator, nil)
+	assetTransfer := &chaincode.SmartContract{}
+	history, err := assetTransfer.GetAssetHistory(transactionContext, "asset1")
+	require.NoError(t, err)
+	require.Equal(t, []chaincode.HistoryQueryResult{
+		{Record: &chaincode.Asset{ID: "asset1"}, TxId: "tx2", Timestamp: time.Unix(200, 0).UTC(), IsDelete: true},
+		{Record: asset, TxId: "tx1", Timestamp: time.Unix(100, 0).UTC()},
+	}, history)
+	require.Equal(t, "asset1", chaincodeStub.GetHistoryForKeyArgsForCall(0))
+
+	iterator.HasNextReturns(true)
+	iterator.NextReturns(nil, fmt.Errorf("failed retrieving next item"))
+	history, err = assetTransfer.GetAssetHistory(transactionContext, "asset1")
+	require.EqualError(t, err, "failed retrieving next item")
+	require.Nil(t, history)
+
+	chaincodeStub.GetHistoryForKeyReturns(nil, fmt.Errorf("failed retrieving history"))
+	history, err = assetTransfer.GetAssetHistory(transactionContext, "asset1")
+	require.EqualError(t, err, "failed retrieving history")
+	require.Nil(t, history)
+}
+
+func TestReadAssetAsOf(t *testing.T) {
+	created := &chaincode.Asset{ID: "asset1", Owner: "Tomoko"}
+	createdBytes, err := json.Marshal(created)
+	require.NoError(t, err)
+	transferred := &chaincode.Asset{ID: "asset1", Owner: "Brad"}
+	transferredBytes, err := json.Marshal(transferred)
+	require.NoError(t, err)
+
+	newIterator := func() *mocks.HistoryQueryIterator {
+		iterator := &mocks.HistoryQueryIterator{}
+		iterator.HasNextReturnsOnCall(0, true)
+		iterator.HasNextReturnsOnCall(1, true)
+		iterator.HasNextReturnsOnCall(2, true)
+		iterator.HasNextReturnsOnCall(3, false)
+		iterator.NextReturnsOnCall(0, &queryresult.KeyModification{TxId: "tx3", Timestamp: &timestamp.Timestamp{Seconds: 300}, IsDelete: true}, nil)
+		iterator.NextReturnsOnCall(1, &queryresult.KeyModification{TxId: "tx2", Value: transferredBytes, Timestamp: &timestamp.Timestamp{Seconds: 200}}, nil)
+		iterator.NextReturnsOnCall(2, &queryresult.KeyModification{TxId: "tx1", Value: createdBytes, Timestamp: &timestamp.Timestamp{Seconds: 100}}, nil)
+		return iterator
+	}
+	asOf := func(seconds int64) string {
+		return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+	}
+
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	assetTransfer := &chaincode.SmartContract{}
+
+	chaincodeStub.GetHistoryForKeyReturns(newIterator(), nil)
+	result, err := assetTransfer.ReadAssetAsOf(transactionContext, "asset1", asOf(150))
+	require.NoError(t, err)
+	require.Equal(t, created, result.Record)
+	require.Equal(t, "tx1", result.TxId)
+	require.False(t, result.IsDelete)
+
+	chaincodeStub.GetHistoryForKeyReturns(newIterator(), nil)
+	result, err = assetTransfer.ReadAssetAsOf(transactionContext, "asset1", asOf(200))
+	require.NoError(t, err)
+	require.Equal(t, transferred, result.Record)
+	require.Equal(t, "tx2", result.TxId)
+
+	chaincodeStub.GetHistoryForKeyReturns(newIterator(), nil)
+	result, err = assetTransfer.ReadAssetAsOf(transactionContext, "asset1", asOf(400))
+	require.NoError(t, err)
+	require.Equal(t, "tx3", result.TxId)
+	require.True(t, result.IsDelete)
+
+	chaincodeStub.GetHistoryForKeyReturns(newIterator(), nil)
+	result, err = assetTransfer.ReadAssetAsOf(transactionContext, "asset1", asOf(50))
+	require.EqualError(t, err, fmt.Sprintf("the asset asset1 did not exist at %s", asOf(50)))
+	require.Nil(t, result)
+
+	_, err = assetTransfer.ReadAssetAsOf(transactionContext, "asset1", "yesterday")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid timestamp yesterday, expected RFC 3339 format")
+
+	chaincodeStub.GetHistoryForKeyReturns(nil, fmt.Errorf("failed retrieving history"))
+	result, err = assetTransfer.ReadAssetAsOf(transactionContext, "asset1", asOf(150))
+	require.EqualError(t, err, "failed retrieving history")
+	require.Nil(t, result)
+}
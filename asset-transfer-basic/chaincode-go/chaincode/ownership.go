@@ -0,0 +1,280 @@
+package chaincode
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// configKeyPrefix is the composite key object type of the contract configuration.
+// Composite keys are not returned by the asset range queries.
+const configKeyPrefix = "config"
+
+// clientIDPrefix starts the client IDs of X.509 identities, once decoded from base64
+const clientIDPrefix = "x509::"
+
+// ContractConfig is the contract configuration chosen when the chaincode is initialized
+type ContractConfig struct {
+	// EnforceOwnership binds asset owners to client identities: the owner of an asset must then
+	// be the client ID or the MSP ID of a client, and only the owner or one of its approved
+	// delegates can update, transfer or delete the asset.
+	EnforceOwnership bool `json:"enforceOwnership"`
+	// OwnerMSPIDs lists the MSP IDs that assets can be updated or transferred to when ownership
+	// is enforced, in addition to client IDs and the MSP ID of the submitting client.
+	OwnerMSPIDs []string `json:"ownerMSPIDs,omitempty"`
+	// MaxCreateBatchSize limits the number of assets accepted by CreateAssetsBatch.
+	// DefaultMaxBatchSize is used when it is not set.
+	MaxCreateBatchSize int `json:"maxCreateBatchSize,omitempty"`
+	// MaxTransferBatchSize limits the number of transfers accepted by TransferAssetsBatch.
+	// DefaultMaxBatchSize is used when it is not set.
+	MaxTransferBatchSize int `json:"maxTransferBatchSize,omitempty"`
+	// AdminMSPID is the MSP ID of the clients allowed to run admin functions such as MigrateAssets.
+	// DefaultAdminMSPID is used when it is not set.
+	AdminMSPID string `json:"adminMSPID,omitempty"`
+}
+
+// Initialize stores the contract configuration. It is meant to be run as the chaincode
+// init function and can only be run once, by a client of the admin MSP.
+func (s *SmartContract) Initialize(ctx contractapi.TransactionContextInterface, enforceOwnership bool, ownerMSPIDs []string) error {
+	err := s.assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config != nil {
+		return fmt.Errorf("the contract is already initialized")
+	}
+
+	return putConfig(ctx, &ContractConfig{EnforceOwnership: enforceOwnership, OwnerMSPIDs: ownerMSPIDs})
+}
+
+// GetConfig returns the contract configuration. The zero configuration is
+// returned when the contract has not been initialized.
+func (s *SmartContract) GetConfig(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	config, err := readConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return &ContractConfig{}, nil
+	}
+
+	return config, nil
+}
+
+// updateConfig applies the change to the configuration of an initialized contract,
+// on behalf of a client of the admin MSP
+func (s *SmartContract) updateConfig(ctx contractapi.TransactionContextInterface, change func(*ContractConfig) error) error {
+	err := s.assertAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	config, err := readConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return fmt.Errorf("the contract must be initialized first")
+	}
+	err = change(config)
+	if err != nil {
+		return err
+	}
+
+	return putConfig(ctx, config)
+}
+
+// readConfig returns the stored contract configuration, or nil when the contract has not been initialized
+func readConfig(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	configKey, err := ctx.GetStub().CreateCompositeKey(configKeyPrefix, []string{})
+	if err != nil {
+		return nil, err
+	}
+	configJSON, err := ctx.GetStub().GetState(configKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(configJSON) == 0 {
+		return nil, nil
+	}
+
+	var config ContractConfig
+	err = json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// putConfig stores the contract configuration
+func putConfig(ctx contractapi.TransactionContextInterface, config *ContractConfig) error {
+	configKey, err := ctx.GetStub().CreateCompositeKey(configKeyPrefix, []string{})
+	if err != nil {
+		return err
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(configKey, configJSON)
+}
+
+// ApproveDelegate allows the delegate, a client ID or an MSP ID, to update, transfer or delete
+// the asset on behalf of its owner. Only available when ownership is enforced.
+func (s *SmartContract) ApproveDelegate(ctx contractapi.TransactionContextInterface, id string, delegate string) error {
+	return s.changeDelegates(ctx, id, func(delegates []string) ([]string, error) {
+		for _, d := range delegates {
+			if d == delegate {
+				return nil, fmt.Errorf("%s is already a delegate of the asset %s", delegate, id)
+			}
+		}
+		return append(delegates, delegate), nil
+	})
+}
+
+// RevokeDelegate withdraws a delegation granted with ApproveDelegate.
+// Only available when ownership is enforced.
+func (s *SmartContract) RevokeDelegate(ctx contractapi.TransactionContextInterface, id string, delegate string) error {
+	return s.changeDelegates(ctx, id, func(delegates []string) ([]string, error) {
+		for i, d := range delegates {
+			if d == delegate {
+				return append(delegates[:i:i], delegates[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%s is not a delegate of the asset %s", delegate, id)
+	})
+}
+
+// changeDelegates applies the change to the delegates of an asset on behalf of its owner
+func (s *SmartContract) changeDelegates(ctx contractapi.TransactionContextInterface, id string, change func([]string) ([]string, error)) error {
+	guard, err := s.newOwnershipGuard(ctx)
+	if err != nil {
+		return err
+	}
+	if !guard.enforced {
+		return fmt.Errorf("delegates can only be approved when ownership is enforced")
+	}
+
+	before, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !guard.isOwner(before) {
+		return fmt.Errorf("client %s is not the owner of the asset %s", guard.clientID, id)
+	}
+
+	asset := *before
+	asset.Delegates, err = change(before.Delegates)
+	if err != nil {
+		return err
+	}
+	asset.Revision++
+	err = putAsset(ctx, &asset)
+	if err != nil {
+		return err
+	}
+
+	return emitAssetEvent(ctx, AssetUpdatedEvent, before, &asset)
+}
+
+// ownershipGuard checks the submitting client against asset owners and delegates
+type ownershipGuard struct {
+	enforced    bool
+	clientID    string
+	clientMSPID string
+	ownerMSPIDs []string
+}
+
+// newOwnershipGuard returns the guard of the submitting client for the configured ownership mode
+func (s *SmartContract) newOwnershipGuard(ctx contractapi.TransactionContextInterface) (*ownershipGuard, error) {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !config.EnforceOwnership {
+		return &ownershipGuard{}, nil
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+
+	return &ownershipGuard{enforced: true, clientID: clientID, clientMSPID: clientMSPID, ownerMSPIDs: config.OwnerMSPIDs}, nil
+}
+
+// matches returns true when the principal is the client ID or the MSP ID of the submitting client
+func (g *ownershipGuard) matches(principal string) bool {
+	return principal == g.clientID || principal == g.clientMSPID
+}
+
+// isOwner returns true when the submitting client owns the asset
+func (g *ownershipGuard) isOwner(asset *Asset) bool {
+	return g.matches(asset.Owner)
+}
+
+// checkOwner returns an error when ownership is enforced and the submitting
+// client cannot issue an asset to the given owner
+func (g *ownershipGuard) checkOwner(owner string) error {
+	if !g.enforced || g.matches(owner) {
+		return nil
+	}
+
+	return fmt.Errorf("the owner %s must be the client ID %s or the MSP ID %s of the submitting client", owner, g.clientID, g.clientMSPID)
+}
+
+// checkNewOwner returns an error when ownership is enforced and the new owner of an asset
+// is neither a client ID nor a known MSP ID, so that assets can not be sent to a principal
+// no client can act as
+func (g *ownershipGuard) checkNewOwner(owner string) error {
+	if !g.enforced || isClientID(owner) || owner == g.clientMSPID {
+		return nil
+	}
+	for _, mspID := range g.ownerMSPIDs {
+		if owner == mspID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the new owner %s must be a client ID or one of the MSP IDs %s", owner, strings.Join(append([]string{g.clientMSPID}, g.ownerMSPIDs...), ", "))
+}
+
+// isClientID returns true when the principal is the client ID of an X.509 identity, either
+// base64 encoded, as returned by the client identity library, or decoded
+func isClientID(principal string) bool {
+	if strings.HasPrefix(principal, clientIDPrefix) {
+		return true
+	}
+	decoded, err := base64.StdEncoding.DecodeString(principal)
+
+	return err == nil && strings.HasPrefix(string(decoded), clientIDPrefix)
+}
+
+// checkModify returns an error when ownership is enforced and the submitting
+// client is neither the owner nor a delegate of the asset
+func (g *ownershipGuard) checkModify(asset *Asset) error {
+	if !g.enforced || g.isOwner(asset) {
+		return nil
+	}
+	for _, delegate := range asset.Delegates {
+		if g.matches(delegate) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("client %s is not authorized to modify the asset %s", g.clientID, asset.ID)
+}
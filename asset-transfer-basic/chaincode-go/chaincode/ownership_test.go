@@ -0,0 +1,261 @@
+package chaincode_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ownerID   = "x509::CN=owner::CN=ca.org1.example.com"
+	delegate  = "x509::CN=delegate::CN=ca.org1.example.com"
+	strangeID = "x509::CN=stranger::CN=ca.org2.example.com"
+)
+
+// prepOwnershipMocks returns mocks of a client with the given identity, backed by a
+// world state holding the contract configuration and the given assets
+func prepOwnershipMocks(t *testing.T, enforceOwnership bool, id string, mspID string, assets ...*chaincode.Asset) (*mocks.TransactionContext, *mocks.ChaincodeStub) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(id, nil)
+	clientIdentity.GetMSPIDReturns(mspID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
+
+	chaincodeStub.CreateCompositeKeyStub = func(objectType string, attributes []string) (string, error) {
+		return "\x00" + objectType + "\x00" + strings.Join(attributes, "\x00"), nil
+	}
+	configJSON, err := json.Marshal(chaincode.ContractConfig{EnforceOwnership: enforceOwnership, OwnerMSPIDs: []string{"Org2MSP"}})
+	require.NoError(t, err)
+	worldState := map[string][]byte{"\x00config\x00": configJSON}
+	for _, asset := range assets {
+		assetJSON, err := json.Marshal(asset)
+		require.NoError(t, err)
+		worldState[asset.ID] = assetJSON
+	}
+	chaincodeStub.GetStateStub = func(key string) ([]byte, error) {
+		return worldState[key], nil
+	}
+
+	return transactionContext, chaincodeStub
+}
+
+func TestInitialize(t *testing.T) {
+	transactionContext, chaincodeStub := prepOwnershipMocks(t, false, ownerID, chaincode.DefaultAdminMSPID)
+	chaincodeStub.GetStateStub = nil
+	assetTransfer := chaincode.SmartContract{}
+
+	chaincodeStub.CreateCompositeKeyReturns("\x00config\x00", nil)
+	err := assetTransfer.Initialize(transactionContext, true, []string{"Org2MSP"})
+	require.NoError(t, err)
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "\x00config\x00", key)
+	require.JSONEq(t, `{"enforceOwnership":true,"ownerMSPIDs":["Org2MSP"]}`, string(value))
+
+	chaincodeStub.GetStateReturns(value, nil)
+	config, err := assetTransfer.GetConfig(transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ContractConfig{EnforceOwnership: true, OwnerMSPIDs: []string{"Org2MSP"}}, config)
+
+	err = assetTransfer.Initialize(transactionContext, false, nil)
+	require.EqualError(t, err, "the contract is already initialized")
+
+	chaincodeStub.GetStateReturns(nil, nil)
+	config, err = assetTransfer.GetConfig(transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.ContractConfig{}, config)
+
+	transactionContext, _ = prepOwnershipMocks(t, false, strangeID, "Org2MSP")
+	err = assetTransfer.Initialize(transactionContext, true, nil)
+	require.EqualError(t, err, "client of MSP Org2MSP is not authorized to run admin functions")
+}
+
+func TestEnforcedOwnershipPermissions(t *testing.T) {
+	assetTransfer := chaincode.SmartContract{}
+	operations := map[string]func(*mocks.TransactionContext) error{
+		"UpdateAsset": func(ctx *mocks.TransactionContext) error {
+			return assetTransfer.UpdateAsset(ctx, "asset1", "red", 5, ownerID, 300)
+		},
+		"UpdateAssetIfRevision": func(ctx *mocks.TransactionContext) error {
+			return assetTransfer.UpdateAssetIfRevision(ctx, "asset1", "red", 5, ownerID, 300, 1)
+		},
+		"TransferAsset": func(ctx *mocks.TransactionContext) error {
+			return assetTransfer.TransferAsset(ctx, "asset1", strangeID)
+		},
+		"TransferAssetIfRevision": func(ctx *mocks.TransactionContext) error {
+			return assetTransfer.TransferAssetIfRevision(ctx, "asset1", strangeID, 1)
+		},
+		"DeleteAsset": func(ctx *mocks.TransactionContext) error {
+			return assetTransfer.DeleteAsset(ctx, "asset1")
+		},
+	}
+
+	tests := []struct {
+		name             string
+		enforceOwnership bool
+		owner            string
+		delegates        []string
+		clientID         string
+		clientMSPID      string
+		allowed          bool
+	}{
+		{name: "owner client ID", enforceOwnership: true, owner: ownerID, clientID: ownerID, clientMSPID: "Org1MSP", allowed: true},
+		{name: "owner MSP ID", enforceOwnership: true, owner: "Org1MSP", clientID: delegate, clientMSPID: "Org1MSP", allowed: true},
+		{name: "delegate client ID", enforceOwnership: true, owner: ownerID, delegates: []string{delegate}, clientID: delegate, clientMSPID: "Org1MSP", allowed: true},
+		{name: "delegate MSP ID", enforceOwnership: true, owner: ownerID, delegates: []string{"Org2MSP"}, clientID: strangeID, clientMSPID: "Org2MSP", allowed: true},
+		{name: "other client of owner MSP", enforceOwnership: true, owner: ownerID, clientID: delegate, clientMSPID: "Org1MSP", allowed: false},
+		{name: "stranger", enforceOwnership: true, owner: ownerID, delegates: []string{delegate}, clientID: strangeID, clientMSPID: "Org2MSP", allowed: false},
+		{name: "stranger without enforcement", enforceOwnership: false, owner: ownerID, clientID: strangeID, clientMSPID: "Org2MSP", allowed: true},
+	}
+
+	for _, tt := range tests {
+		for operation, invoke := range operations {
+			t.Run(tt.name+"/"+operation, func(t *testing.T) {
+				asset := &chaincode.Asset{ID: "asset1", Owner: tt.owner, Delegates: tt.delegates, Revision: 1}
+				transactionContext, chaincodeStub := prepOwnershipMocks(t, tt.enforceOwnership, tt.clientID, tt.clientMSPID, asset)
+
+				err := invoke(transactionContext)
+				if tt.allowed {
+					require.NoError(t, err)
+				} else {
+					require.EqualError(t, err, fmt.Sprintf("client %s is not authorized to modify the asset asset1", tt.clientID))
+					require.Zero(t, chaincodeStub.PutStateCallCount())
+					require.Zero(t, chaincodeStub.DelStateCallCount())
+				}
+			})
+		}
+	}
+}
+
+func TestEnforcedOwnershipCreate(t *testing.T) {
+	assetTransfer := chaincode.SmartContract{}
+
+	transactionContext, _ := prepOwnershipMocks(t, true, ownerID, "Org1MSP")
+	err := assetTransfer.CreateAsset(transactionContext, "asset1", "blue", 5, ownerID, 300)
+	require.NoError(t, err)
+	err = assetTransfer.CreateAsset(transactionContext, "asset1", "blue", 5, "Org1MSP", 300)
+	require.NoError(t, err)
+	err = assetTransfer.CreateAsset(transactionContext, "asset1", "blue", 5, "Tomoko", 300)
+	require.EqualError(t, err, fmt.Sprintf("the owner Tomoko must be the client ID %s or the MSP ID Org1MSP of the submitting client", ownerID))
+
+	err = assetTransfer.CreateAssetsBatch(transactionContext, fmt.Sprintf(`[{"ID":"asset1","owner":"%s"},{"ID":"asset2","owner":"Tomoko"}]`, ownerID))
+	requireBatchError(t, err, chaincode.BatchItemError{
+		Index: 1,
+		ID:    "asset2",
+		Error: fmt.Sprintf("the owner Tomoko must be the client ID %s or the MSP ID Org1MSP of the submitting client", ownerID),
+	})
+
+	transactionContext, _ = prepOwnershipMocks(t, false, ownerID, "Org1MSP")
+	err = assetTransfer.CreateAsset(transactionContext, "asset1", "blue", 5, "Tomoko", 300)
+	require.NoError(t, err)
+}
+
+func TestEnforcedOwnershipNewOwner(t *testing.T) {
+	assetTransfer := chaincode.SmartContract{}
+	asset := &chaincode.Asset{ID: "asset1", Owner: ownerID, Revision: 1}
+	encodedID := base64.StdEncoding.EncodeToString([]byte(strangeID))
+
+	for _, newOwner := range []string{strangeID, encodedID, "Org1MSP", "Org2MSP"} {
+		transactionContext, _ := prepOwnershipMocks(t, true, ownerID, "Org1MSP", asset)
+		require.NoError(t, assetTransfer.TransferAsset(transactionContext, "asset1", newOwner), newOwner)
+		require.NoError(t, assetTransfer.UpdateAsset(transactionContext, "asset1", "red", 5, newOwner, 300), newOwner)
+	}
+
+	transactionContext, chaincodeStub := prepOwnershipMocks(t, true, ownerID, "Org1MSP", asset)
+	err := assetTransfer.TransferAsset(transactionContext, "asset1", "Tomoko")
+	require.EqualError(t, err, "the new owner Tomoko must be a client ID or one of the MSP IDs Org1MSP, Org2MSP")
+	err = assetTransfer.UpdateAsset(transactionContext, "asset1", "red", 5, "Org3MSP", 300)
+	require.EqualError(t, err, "the new owner Org3MSP must be a client ID or one of the MSP IDs Org1MSP, Org2MSP")
+	err = assetTransfer.TransferAssetsBatch(transactionContext, `[{"ID":"asset1","newOwner":"Tomoko"}]`)
+	requireBatchError(t, err, chaincode.BatchItemError{
+		Index: 0,
+		ID:    "asset1",
+		Error: "the new owner Tomoko must be a client ID or one of the MSP IDs Org1MSP, Org2MSP",
+	})
+	require.Zero(t, chaincodeStub.PutStateCallCount())
+
+	// Owners are not checked when ownership is not enforced
+	transactionContext, _ = prepOwnershipMocks(t, false, ownerID, "Org1MSP", asset)
+	require.NoError(t, assetTransfer.TransferAsset(transactionContext, "asset1", "Tomoko"))
+}
+
+func TestEnforcedOwnershipTransferBatch(t *testing.T) {
+	assetTransfer := chaincode.SmartContract{}
+	owned := &chaincode.Asset{ID: "asset1", Owner: ownerID, Delegates: []string{delegate}, Revision: 1}
+	other := &chaincode.Asset{ID: "asset2", Owner: strangeID, Revision: 1}
+
+	transactionContext, chaincodeStub := prepOwnershipMocks(t, true, ownerID, "Org1MSP", owned, other)
+	err := assetTransfer.TransferAssetsBatch(transactionContext, `[{"ID":"asset1","newOwner":"Org2MSP"},{"ID":"asset2","newOwner":"Org1MSP"}]`)
+	requireBatchError(t, err, chaincode.BatchItemError{
+		Index: 1,
+		ID:    "asset2",
+		Error: fmt.Sprintf("client %s is not authorized to modify the asset asset2", ownerID),
+	})
+	require.Zero(t, chaincodeStub.PutStateCallCount())
+
+	err = assetTransfer.TransferAssetsBatch(transactionContext, `[{"ID":"asset1","newOwner":"Org2MSP"}]`)
+	require.NoError(t, err)
+	_, value := chaincodeStub.PutStateArgsForCall(0)
+	require.NotContains(t, string(value), "delegates")
+}
+
+func TestDelegates(t *testing.T) {
+	assetTransfer := chaincode.SmartContract{}
+	asset := &chaincode.Asset{ID: "asset1", Owner: clientID, Revision: 1}
+
+	transactionContext, chaincodeStub := prepOwnershipMocks(t, true, clientID, "Org1MSP", asset)
+	err := assetTransfer.ApproveDelegate(transactionContext, "asset1", delegate)
+	require.NoError(t, err)
+	requireAssetEvent(t, chaincodeStub, chaincode.AssetUpdatedEvent, asset,
+		&chaincode.Asset{ID: "asset1", Owner: clientID, Delegates: []string{delegate}, Revision: 2})
+
+	delegated := &chaincode.Asset{ID: "asset1", Owner: clientID, Delegates: []string{delegate, "Org2MSP"}, Revision: 2}
+	transactionContext, chaincodeStub = prepOwnershipMocks(t, true, clientID, "Org1MSP", delegated)
+	err = assetTransfer.ApproveDelegate(transactionContext, "asset1", delegate)
+	require.EqualError(t, err, fmt.Sprintf("%s is already a delegate of the asset asset1", delegate))
+
+	err = assetTransfer.RevokeDelegate(transactionContext, "asset1", delegate)
+	require.NoError(t, err)
+	requireAssetEvent(t, chaincodeStub, chaincode.AssetUpdatedEvent, delegated,
+		&chaincode.Asset{ID: "asset1", Owner: clientID, Delegates: []string{"Org2MSP"}, Revision: 3})
+
+	err = assetTransfer.RevokeDelegate(transactionContext, "asset1", strangeID)
+	require.EqualError(t, err, fmt.Sprintf("%s is not a delegate of the asset asset1", strangeID))
+
+	transactionContext, _ = prepOwnershipMocks(t, true, delegate, "Org1MSP", delegated)
+	err = assetTransfer.ApproveDelegate(transactionContext, "asset1", strangeID)
+	require.EqualError(t, err, fmt.Sprintf("client %s is not the owner of the asset asset1", delegate))
+
+	transactionContext, _ = prepOwnershipMocks(t, false, clientID, "Org1MSP", asset)
+	err = assetTransfer.ApproveDelegate(transactionContext, "asset1", delegate)
+	require.EqualError(t, err, "delegates can only be approved when ownership is enforced")
+}
+
+func TestSetAdminMSPID(t *testing.T) {
+	assetTransfer := chaincode.SmartContract{}
+
+	transactionContext, chaincodeStub := prepOwnershipMocks(t, false, ownerID, chaincode.DefaultAdminMSPID)
+	err := assetTransfer.SetAdminMSPID(transactionContext, "Org2MSP")
+	require.NoError(t, err)
+	_, value := chaincodeStub.PutStateArgsForCall(0)
+	require.JSONEq(t, `{"enforceOwnership":false,"ownerMSPIDs":["Org2MSP"],"adminMSPID":"Org2MSP"}`, string(value))
+
+	// The previous admin MSP loses the admin functions
+	chaincodeStub.GetStateReturns(value, nil)
+	err = assetTransfer.SetAdminMSPID(transactionContext, chaincode.DefaultAdminMSPID)
+	require.EqualError(t, err, "client of MSP Org1MSP is not authorized to run admin functions")
+
+	err = assetTransfer.SetAdminMSPID(transactionContext, "")
+	require.EqualError(t, err, "admin MSP ID must not be empty")
+
+	chaincodeStub.GetStateReturns(nil, nil)
+	err = assetTransfer.SetAdminMSPID(transactionContext, "Org2MSP")
+	require.EqualError(t, err, "the contract must be initialized first")
+}
@@ -0,0 +1,57 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// StaleRevisionErrorCode prefixes the message of a StaleRevisionError, so that
+// clients can tell a rejected stale write from other failures and retry it.
+const StaleRevisionErrorCode = "STALE_REVISION"
+
+// anyRevision disables the revision check of a write
+const anyRevision = -1
+
+// StaleRevisionError is returned when a conditional write expects a revision
+// other than the one currently stored on the ledger
+type StaleRevisionError struct {
+	ID               string
+	ExpectedRevision int
+	Revision         int
+}
+
+func (e *StaleRevisionError) Error() string {
+	return fmt.Sprintf("%s: the asset %s is at revision %d, expected revision %d", StaleRevisionErrorCode, e.ID, e.Revision, e.ExpectedRevision)
+}
+
+// UpdateAssetIfRevision updates an existing asset like UpdateAsset, but only if the asset
+// is still at the expected revision. Otherwise it fails with a StaleRevisionError and the
+// client should read the asset again before retrying.
+func (s *SmartContract) UpdateAssetIfRevision(ctx contractapi.TransactionContextInterface, id string, color string, size int, owner string, appraisedValue int, expectedRevision int) error {
+	if expectedRevision < 0 {
+		return fmt.Errorf("expected revision must not be negative, got %d", expectedRevision)
+	}
+
+	return s.updateAsset(ctx, id, color, size, owner, appraisedValue, expectedRevision)
+}
+
+// TransferAssetIfRevision updates the owner of an asset like TransferAsset, but only if the
+// asset is still at the expected revision. Otherwise it fails with a StaleRevisionError and
+// the client should read the asset again before retrying.
+func (s *SmartContract) TransferAssetIfRevision(ctx contractapi.TransactionContextInterface, id string, newOwner string, expectedRevision int) error {
+	if expectedRevision < 0 {
+		return fmt.Errorf("expected revision must not be negative, got %d", expectedRevision)
+	}
+
+	return s.transferAsset(ctx, id, newOwner, expectedRevision)
+}
+
+// checkRevision returns a StaleRevisionError when the asset is not at the expected revision
+func checkRevision(asset *Asset, expectedRevision int) error {
+	if expectedRevision == anyRevision || asset.Revision == expectedRevision {
+		return nil
+	}
+
+	return &StaleRevisionError{ID: asset.ID, ExpectedRevision: expectedRevision, Revision: asset.Revision}
+}
@@ -0,0 +1,72 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAssetIfRevision(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
+
+	asset := &chaincode.Asset{ID: "asset1", Owner: "Tomoko", Revision: 3}
+	bytes, err := json.Marshal(asset)
+	require.NoError(t, err)
+
+	chaincodeStub.GetStateReturns(bytes, nil)
+	assetTransfer := chaincode.SmartContract{}
+	err = assetTransfer.UpdateAssetIfRevision(transactionContext, "asset1", "red", 10, "Brad", 400, 3)
+	require.NoError(t, err)
+	requireAssetEvent(t, chaincodeStub, chaincode.AssetUpdatedEvent, asset,
+		&chaincode.Asset{ID: "asset1", Color: "red", Size: 10, Owner: "Brad", AppraisedValue: 400, Revision: 4})
+
+	err = assetTransfer.UpdateAssetIfRevision(transactionContext, "asset1", "red", 10, "Brad", 400, 2)
+	require.EqualError(t, err, "STALE_REVISION: the asset asset1 is at revision 3, expected revision 2")
+	staleErr, ok := err.(*chaincode.StaleRevisionError)
+	require.True(t, ok)
+	require.Equal(t, &chaincode.StaleRevisionError{ID: "asset1", ExpectedRevision: 2, Revision: 3}, staleErr)
+	require.Equal(t, 1, chaincodeStub.PutStateCallCount())
+
+	err = assetTransfer.UpdateAssetIfRevision(transactionContext, "asset1", "red", 10, "Brad", 400, -1)
+	require.EqualError(t, err, "expected revision must not be negative, got -1")
+
+	chaincodeStub.GetStateReturns(nil, nil)
+	err = assetTransfer.UpdateAssetIfRevision(transactionContext, "asset1", "red", 10, "Brad", 400, 3)
+	require.EqualError(t, err, "the asset asset1 does not exist")
+}
+
+func TestTransferAssetIfRevision(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
+
+	asset := &chaincode.Asset{ID: "asset1", Owner: "Tomoko", Revision: 1}
+	bytes, err := json.Marshal(asset)
+	require.NoError(t, err)
+
+	chaincodeStub.GetStateReturns(bytes, nil)
+	assetTransfer := chaincode.SmartContract{}
+	err = assetTransfer.TransferAssetIfRevision(transactionContext, "asset1", "Max", 1)
+	require.NoError(t, err)
+	requireAssetEvent(t, chaincodeStub, chaincode.AssetTransferredEvent, asset, &chaincode.Asset{ID: "asset1", Owner: "Max", Revision: 2})
+
+	err = assetTransfer.TransferAssetIfRevision(transactionContext, "asset1", "Max", 0)
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), chaincode.StaleRevisionErrorCode))
+	require.Equal(t, 1, chaincodeStub.PutStateCallCount())
+
+	err = assetTransfer.TransferAssetIfRevision(transactionContext, "asset1", "Max", -1)
+	require.EqualError(t, err, "expected revision must not be negative, got -1")
+}
@@ -0,0 +1,211 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AssetSchemaVersion is the schema version of the asset records written by this chaincode.
+// Records written before schema versioning was introduced carry no version and are read as version 0.
+//
+// To change the layout of Asset, increment AssetSchemaVersion and register in assetUpgrades
+// a function upgrading records from the previous version, e.g. setting a default currency
+// on records that predate a Currency field.
+const AssetSchemaVersion = 3
+
+// assetUpgradeFunc upgrades a raw asset record from the version it is registered under to the next version
+type assetUpgradeFunc func(record map[string]interface{}) error
+
+// assetUpgrades holds the upgrade functions, keyed by the schema version they upgrade from
+var assetUpgrades = map[int]assetUpgradeFunc{
+	// version 0 records are bare Asset JSON, which only lacks the schema version
+	0: func(record map[string]interface{}) error { return nil },
+	// version 2 adds the revision counter, which starts at 0 for records written before it existed
+	1: func(record map[string]interface{}) error {
+		if _, ok := record["revision"]; !ok {
+			record["revision"] = 0
+		}
+		return nil
+	},
+	// version 3 adds the optional delegates list, which is empty for records written before it existed
+	2: func(record map[string]interface{}) error { return nil },
+}
+
+// DefaultAdminMSPID is the MSP ID of the clients allowed to run admin functions
+// when the contract configuration does not set its own.
+const DefaultAdminMSPID = "Org1MSP"
+
+// assetRecord is the layout of an asset in the world state
+type assetRecord struct {
+	SchemaVersion int `json:"schemaVersion"`
+	Asset
+}
+
+// MigrationResult structure used for returning the outcome of a MigrateAssets page
+type MigrationResult struct {
+	ScannedRecordsCount  int    `json:"scannedRecordsCount"`
+	MigratedRecordsCount int    `json:"migratedRecordsCount"`
+	NextStartKey         string `json:"nextStartKey"`
+}
+
+// marshalAsset returns the world state representation of the asset at the current schema version
+func marshalAsset(asset *Asset) ([]byte, error) {
+	return json.Marshal(assetRecord{SchemaVersion: AssetSchemaVersion, Asset: *asset})
+}
+
+// putAsset writes the asset to the world state at the current schema version
+func putAsset(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+	assetJSON, err := marshalAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(asset.ID, assetJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return nil
+}
+
+// unmarshalAsset decodes an asset record of any known schema version, upgrading it
+// in memory to the current version. It returns the schema version the record was stored at.
+func unmarshalAsset(assetJSON []byte) (*Asset, int, error) {
+	var record assetRecord
+	err := json.Unmarshal(assetJSON, &record)
+	if err != nil {
+		return nil, 0, err
+	}
+	if record.SchemaVersion == AssetSchemaVersion {
+		return &record.Asset, record.SchemaVersion, nil
+	}
+	if record.SchemaVersion > AssetSchemaVersion {
+		return nil, 0, fmt.Errorf("unsupported asset schema version %d, latest known version is %d", record.SchemaVersion, AssetSchemaVersion)
+	}
+
+	var raw map[string]interface{}
+	err = json.Unmarshal(assetJSON, &raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	for version := record.SchemaVersion; version < AssetSchemaVersion; version++ {
+		upgrade, ok := assetUpgrades[version]
+		if !ok {
+			return nil, 0, fmt.Errorf("no upgrade registered for asset schema version %d", version)
+		}
+		err = upgrade(raw)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to upgrade asset from schema version %d: %v", version, err)
+		}
+	}
+	raw["schemaVersion"] = AssetSchemaVersion
+
+	upgradedJSON, err := json.Marshal(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	var upgraded assetRecord
+	err = json.Unmarshal(upgradedJSON, &upgraded)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &upgraded.Asset, record.SchemaVersion, nil
+}
+
+// MigrateAssets rewrites asset records stored at an older schema version at the current version.
+// At most pageSize records are scanned, starting with startKey; the returned NextStartKey resumes
+// the migration in a following transaction and is empty once every record has been scanned.
+// Only clients of the admin MSP can run a migration.
+func (s *SmartContract) MigrateAssets(ctx contractapi.TransactionContextInterface, startKey string, pageSize int) (*MigrationResult, error) {
+	err := s.assertAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	// paginated queries are not allowed in update transactions, so the page is bounded by hand
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	result := &MigrationResult{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if result.ScannedRecordsCount == pageSize {
+			result.NextStartKey = queryResponse.Key
+			break
+		}
+		result.ScannedRecordsCount++
+
+		asset, version, err := unmarshalAsset(queryResponse.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s: %v", queryResponse.Key, err)
+		}
+		if version == AssetSchemaVersion {
+			continue
+		}
+
+		assetJSON, err := marshalAsset(asset)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().PutState(queryResponse.Key, assetJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to put to world state. %v", err)
+		}
+		result.MigratedRecordsCount++
+	}
+
+	return result, nil
+}
+
+// assertAdmin returns an error unless the submitting client belongs to the admin MSP
+func (s *SmartContract) assertAdmin(ctx contractapi.TransactionContextInterface) error {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != config.adminMSPID() {
+		return fmt.Errorf("client of MSP %s is not authorized to run admin functions", clientMSPID)
+	}
+
+	return nil
+}
+
+// SetAdminMSPID hands the admin functions over to the clients of another MSP, by storing its MSP ID
+// in the contract configuration. Only a client of the current admin MSP can change it, once the
+// contract is initialized.
+func (s *SmartContract) SetAdminMSPID(ctx contractapi.TransactionContextInterface, adminMSPID string) error {
+	if adminMSPID == "" {
+		return fmt.Errorf("admin MSP ID must not be empty")
+	}
+
+	return s.updateConfig(ctx, func(config *ContractConfig) error {
+		config.AdminMSPID = adminMSPID
+		return nil
+	})
+}
+
+// adminMSPID returns the configured MSP ID of the clients allowed to run admin functions
+func (c *ContractConfig) adminMSPID() string {
+	if c.AdminMSPID != "" {
+		return c.AdminMSPID
+	}
+
+	return DefaultAdminMSPID
+}
@@ -0,0 +1,94 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAssetSchemaVersions(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	assetTransfer := chaincode.SmartContract{}
+
+	expectedAsset := &chaincode.Asset{ID: "asset1", Color: "blue", Size: 5, Owner: "Tomoko", AppraisedValue: 300}
+
+	chaincodeStub.GetStateReturns([]byte(`{"ID":"asset1","color":"blue","size":5,"owner":"Tomoko","appraisedValue":300}`), nil)
+	asset, err := assetTransfer.ReadAsset(transactionContext, "asset1")
+	require.NoError(t, err)
+	require.Equal(t, expectedAsset, asset)
+
+	chaincodeStub.GetStateReturns([]byte(`{"schemaVersion":1,"ID":"asset1","color":"blue","size":5,"owner":"Tomoko","appraisedValue":300}`), nil)
+	asset, err = assetTransfer.ReadAsset(transactionContext, "asset1")
+	require.NoError(t, err)
+	require.Equal(t, expectedAsset, asset)
+
+	chaincodeStub.GetStateReturns([]byte(`{"schemaVersion":99,"ID":"asset1"}`), nil)
+	asset, err = assetTransfer.ReadAsset(transactionContext, "asset1")
+	require.EqualError(t, err, fmt.Sprintf("unsupported asset schema version 99, latest known version is %d", chaincode.AssetSchemaVersion))
+	require.Nil(t, asset)
+}
+
+func TestMigrateAssets(t *testing.T) {
+	legacyJSON := []byte(`{"ID":"asset1","color":"blue","size":5,"owner":"Tomoko","appraisedValue":300}`)
+	currentJSON, err := json.Marshal(map[string]interface{}{"schemaVersion": chaincode.AssetSchemaVersion, "ID": "asset2"})
+	require.NoError(t, err)
+
+	newIterator := func() *mocks.StateQueryIterator {
+		iterator := &mocks.StateQueryIterator{}
+		iterator.HasNextReturns(true)
+		iterator.HasNextReturnsOnCall(3, false)
+		iterator.NextReturnsOnCall(0, &queryresult.KV{Key: "asset1", Value: legacyJSON}, nil)
+		iterator.NextReturnsOnCall(1, &queryresult.KV{Key: "asset2", Value: currentJSON}, nil)
+		iterator.NextReturnsOnCall(2, &queryresult.KV{Key: "asset3", Value: legacyJSON}, nil)
+		return iterator
+	}
+
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetMSPIDReturns(chaincode.DefaultAdminMSPID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
+	assetTransfer := chaincode.SmartContract{}
+
+	chaincodeStub.GetStateByRangeReturns(newIterator(), nil)
+	result, err := assetTransfer.MigrateAssets(transactionContext, "", 2)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.MigrationResult{ScannedRecordsCount: 2, MigratedRecordsCount: 1, NextStartKey: "asset3"}, result)
+	require.Equal(t, 1, chaincodeStub.PutStateCallCount())
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "asset1", key)
+	require.JSONEq(t, fmt.Sprintf(`{"schemaVersion":%d,"ID":"asset1","color":"blue","size":5,"owner":"Tomoko","appraisedValue":300,"revision":0}`, chaincode.AssetSchemaVersion), string(value))
+
+	chaincodeStub.GetStateByRangeReturns(newIterator(), nil)
+	result, err = assetTransfer.MigrateAssets(transactionContext, "asset3", 10)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.MigrationResult{ScannedRecordsCount: 3, MigratedRecordsCount: 2}, result)
+	startKey, endKey := chaincodeStub.GetStateByRangeArgsForCall(1)
+	require.Equal(t, "asset3", startKey)
+	require.Equal(t, "", endKey)
+
+	_, err = assetTransfer.MigrateAssets(transactionContext, "", 0)
+	require.EqualError(t, err, "page size must be positive, got 0")
+
+	chaincodeStub.GetStateByRangeReturns(nil, fmt.Errorf("failed retrieving assets"))
+	_, err = assetTransfer.MigrateAssets(transactionContext, "", 2)
+	require.EqualError(t, err, "failed retrieving assets")
+
+	clientIdentity.GetMSPIDReturns("Org2MSP", nil)
+	_, err = assetTransfer.MigrateAssets(transactionContext, "", 2)
+	require.EqualError(t, err, "client of MSP Org2MSP is not authorized to run admin functions")
+
+	chaincodeStub.CreateCompositeKeyReturns("\x00config\x00", nil)
+	chaincodeStub.GetStateReturns([]byte(`{"adminMSPID":"Org2MSP"}`), nil)
+	chaincodeStub.GetStateByRangeReturns(newIterator(), nil)
+	_, err = assetTransfer.MigrateAssets(transactionContext, "", 2)
+	require.NoError(t, err)
+}
@@ -1,7 +1,6 @@
 package chaincode
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -19,28 +18,44 @@ type Asset struct {
 	Size           int    `json:"size"`
 	Owner          string `json:"owner"`
 	AppraisedValue int    `json:"appraisedValue"`
+	Revision       int    `json:"revision"`
+	// Delegates lists the client IDs and MSP IDs allowed to act on behalf of
+	// the owner when ownership is enforced. It is cleared on every transfer.
+	Delegates []string `json:"delegates,omitempty"`
 }
 
-// InitLedger adds a base set of assets to the ledger
+// PaginatedQueryResult structure used for returning paginated query results and metadata
+type PaginatedQueryResult struct {
+	Records             []*Asset `json:"records"`
+	FetchedRecordsCount int32    `json:"fetchedRecordsCount"`
+	Bookmark            string   `json:"bookmark"`
+}
+
+// InitLedger adds a base set of assets to the ledger.
+// Assets that already exist are left unchanged, so that running it again can not reset
+// their owners. No events are emitted, since a transaction can only carry a single chaincode event.
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
 	assets := []Asset{
-		{ID: "asset1", Color: "blue", Size: 5, Owner: "Tomoko", AppraisedValue: 300},
-		{ID: "asset2", Color: "red", Size: 5, Owner: "Brad", AppraisedValue: 400},
-		{ID: "asset3", Color: "green", Size: 10, Owner: "Jin Soo", AppraisedValue: 500},
-		{ID: "asset4", Color: "yellow", Size: 10, Owner: "Max", AppraisedValue: 600},
-		{ID: "asset5", Color: "black", Size: 15, Owner: "Adriana", AppraisedValue: 700},
-		{ID: "asset6", Color: "white", Size: 15, Owner: "Michel", AppraisedValue: 800},
+		{ID: "asset1", Color: "blue", Size: 5, Owner: "Tomoko", AppraisedValue: 300, Revision: 1},
+		{ID: "asset2", Color: "red", Size: 5, Owner: "Brad", AppraisedValue: 400, Revision: 1},
+		{ID: "asset3", Color: "green", Size: 10, Owner: "Jin Soo", AppraisedValue: 500, Revision: 1},
+		{ID: "asset4", Color: "yellow", Size: 10, Owner: "Max", AppraisedValue: 600, Revision: 1},
+		{ID: "asset5", Color: "black", Size: 15, Owner: "Adriana", AppraisedValue: 700, Revision: 1},
+		{ID: "asset6", Color: "white", Size: 15, Owner: "Michel", AppraisedValue: 800, Revision: 1},
 	}
 
 	for _, asset := range assets {
-		assetJSON, err := json.Marshal(asset)
+		exists, err := s.AssetExists(ctx, asset.ID)
 		if err != nil {
 			return err
 		}
+		if exists {
+			continue
+		}
 
-		err = ctx.GetStub().PutState(asset.ID, assetJSON)
+		err = putAsset(ctx, &asset)
 		if err != nil {
-			return fmt.Errorf("failed to put to world state. %v", err)
+			return err
 		}
 	}
 
@@ -57,19 +72,29 @@ func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface,
 		return fmt.Errorf("the asset %s already exists", id)
 	}
 
+	guard, err := s.newOwnershipGuard(ctx)
+	if err != nil {
+		return err
+	}
+	err = guard.checkOwner(owner)
+	if err != nil {
+		return err
+	}
+
 	asset := Asset{
 		ID:             id,
 		Color:          color,
 		Size:           size,
 		Owner:          owner,
 		AppraisedValue: appraisedValue,
+		Revision:       1,
 	}
-	assetJSON, err := json.Marshal(asset)
+	err = putAsset(ctx, &asset)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, assetJSON)
+	return emitAssetEvent(ctx, AssetCreatedEvent, nil, &asset)
 }
 
 // ReadAsset returns the asset stored in the world state with given id.
@@ -82,23 +107,34 @@ func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, i
 		return nil, fmt.Errorf("the asset %s does not exist", id)
 	}
 
-	var asset Asset
-	err = json.Unmarshal(assetJSON, &asset)
+	asset, _, err := unmarshalAsset(assetJSON)
 	if err != nil {
 		return nil, err
 	}
 
-	return &asset, nil
+	return asset, nil
 }
 
 // UpdateAsset updates an existing asset in the world state with provided parameters.
 func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface, id string, color string, size int, owner string, appraisedValue int) error {
-	exists, err := s.AssetExists(ctx, id)
+	return s.updateAsset(ctx, id, color, size, owner, appraisedValue, anyRevision)
+}
+
+// updateAsset overwrites an existing asset, provided it is at the expected revision
+func (s *SmartContract) updateAsset(ctx contractapi.TransactionContextInterface, id string, color string, size int, owner string, appraisedValue int, expectedRevision int) error {
+	before, guard, err := s.readAssetForChange(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkRevision(before, expectedRevision)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the asset %s does not exist", id)
+	if owner != before.Owner {
+		err = guard.checkNewOwner(owner)
+		if err != nil {
+			return err
+		}
 	}
 
 	// overwriting original asset with new asset
@@ -108,26 +144,53 @@ func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface,
 		Size:           size,
 		Owner:          owner,
 		AppraisedValue: appraisedValue,
+		Revision:       before.Revision + 1,
 	}
-	assetJSON, err := json.Marshal(asset)
+	if owner == before.Owner {
+		asset.Delegates = before.Delegates
+	}
+	err = putAsset(ctx, &asset)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, assetJSON)
+	return emitAssetEvent(ctx, AssetUpdatedEvent, before, &asset)
 }
 
 // DeleteAsset deletes an given asset from the world state.
 func (s *SmartContract) DeleteAsset(ctx contractapi.TransactionContextInterface, id string) error {
-	exists, err := s.AssetExists(ctx, id)
+	before, _, err := s.readAssetForChange(ctx, id)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the asset %s does not exist", id)
+
+	err = ctx.GetStub().DelState(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state. %v", err)
 	}
 
-	return ctx.GetStub().DelState(id)
+	return emitAssetEvent(ctx, AssetDeletedEvent, before, nil)
+}
+
+// readAssetForChange reads an asset the submitting client is about to modify, along with the
+// ownership guard of the client, returning an error when ownership is enforced and the client
+// is not authorized to.
+func (s *SmartContract) readAssetForChange(ctx contractapi.TransactionContextInterface, id string) (*Asset, *ownershipGuard, error) {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	guard, err := s.newOwnershipGuard(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = guard.checkModify(asset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return asset, guard, nil
 }
 
 // AssetExists returns true when asset with given ID exists in world state
@@ -142,18 +205,34 @@ func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface,
 
 // TransferAsset updates the owner field of asset with given id in world state.
 func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterface, id string, newOwner string) error {
-	asset, err := s.ReadAsset(ctx, id)
+	return s.transferAsset(ctx, id, newOwner, anyRevision)
+}
+
+// transferAsset sets a new owner on an existing asset, provided it is at the expected revision
+func (s *SmartContract) transferAsset(ctx contractapi.TransactionContextInterface, id string, newOwner string, expectedRevision int) error {
+	before, guard, err := s.readAssetForChange(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkRevision(before, expectedRevision)
+	if err != nil {
+		return err
+	}
+	err = guard.checkNewOwner(newOwner)
 	if err != nil {
 		return err
 	}
 
+	asset := *before
 	asset.Owner = newOwner
-	assetJSON, err := json.Marshal(asset)
+	asset.Delegates = nil
+	asset.Revision++
+	err = putAsset(ctx, &asset)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, assetJSON)
+	return emitAssetEvent(ctx, AssetTransferredEvent, before, &asset)
 }
 
 // GetAllAssets returns all assets found in world state
@@ -173,13 +252,89 @@ func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface
 			return nil, err
 		}
 
-		var asset Asset
-		err = json.Unmarshal(queryResponse.Value, &asset)
+		asset, _, err := unmarshalAsset(queryResponse.Value)
 		if err != nil {
 			return nil, err
 		}
-		assets = append(assets, &asset)
+		assets = append(assets, asset)
 	}
 
 	return assets, nil
 }
+
+// GetAssetsWithPagination returns a page of assets found in world state, starting
+// after the given bookmark. The assets on the page can optionally be filtered by
+// owner, color and an appraised value range; empty strings and non-positive values
+// disable the corresponding filter.
+// FetchedRecordsCount reports the number of records read for the page, which may be
+// greater than the number of returned records when filters are applied. An empty
+// bookmark in the result means there are no more pages.
+func (s *SmartContract) GetAssetsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string, owner string, color string, minAppraisedValue int, maxAppraisedValue int) (*PaginatedQueryResult, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+	if maxAppraisedValue > 0 && minAppraisedValue > maxAppraisedValue {
+		return nil, fmt.Errorf("minimum appraised value %d is greater than maximum appraised value %d", minAppraisedValue, maxAppraisedValue)
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByRangeWithPagination("", "", int32(pageSize), bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	filter := assetFilter{
+		owner:             owner,
+		color:             color,
+		minAppraisedValue: minAppraisedValue,
+		maxAppraisedValue: maxAppraisedValue,
+	}
+
+	var assets []*Asset
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		asset, _, err := unmarshalAsset(queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		if filter.matches(asset) {
+			assets = append(assets, asset)
+		}
+	}
+
+	return &PaginatedQueryResult{
+		Records:             assets,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}, nil
+}
+
+// assetFilter holds the optional criteria applied by GetAssetsWithPagination
+type assetFilter struct {
+	owner             string
+	color             string
+	minAppraisedValue int
+	maxAppraisedValue int
+}
+
+// matches returns true when the asset satisfies every criteria set on the filter
+func (f assetFilter) matches(asset *Asset) bool {
+	if f.owner != "" && asset.Owner != f.owner {
+		return false
+	}
+	if f.color != "" && asset.Color != f.color {
+		return false
+	}
+	if f.minAppraisedValue > 0 && asset.AppraisedValue < f.minAppraisedValue {
+		return false
+	}
+	if f.maxAppraisedValue > 0 && asset.AppraisedValue > f.maxAppraisedValue {
+		return false
+	}
+
+	return true
+}
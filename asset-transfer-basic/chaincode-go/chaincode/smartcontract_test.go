@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
 	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
 	"github.com/stretchr/testify/require"
@@ -28,28 +30,86 @@ type stateQueryIterator interface {
 	shim.StateQueryIteratorInterface
 }
 
+//go:generate counterfeiter -o mocks/historyqueryiterator.go -fake-name HistoryQueryIterator . historyQueryIterator
+type historyQueryIterator interface {
+	shim.HistoryQueryIteratorInterface
+}
+
+//go:generate counterfeiter -o mocks/clientIdentity.go -fake-name ClientIdentity . clientIdentity
+type clientIdentity interface {
+	cid.ClientIdentity
+}
+
+const clientID = "x509::CN=User1@org1.example.com::CN=ca.org1.example.com"
+
+// requireAssetEvent asserts that the last chaincode event set on the stub has the given name and payload
+func requireAssetEvent(t *testing.T, chaincodeStub *mocks.ChaincodeStub, eventName string, before *chaincode.Asset, after *chaincode.Asset) {
+	require.NotZero(t, chaincodeStub.SetEventCallCount())
+	name, payload := chaincodeStub.SetEventArgsForCall(chaincodeStub.SetEventCallCount() - 1)
+	require.Equal(t, eventName, name)
+
+	var event chaincode.AssetEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Equal(t, chaincode.AssetEvent{
+		Version:  chaincode.AssetEventVersion,
+		AssetID:  "asset1",
+		Before:   before,
+		After:    after,
+		ClientID: clientID,
+	}, event)
+}
+
 func TestInitLedger(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetMSPIDReturns("Org1MSP", nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
 
 	assetTransfer := chaincode.SmartContract{}
 	err := assetTransfer.InitLedger(transactionContext)
 	require.NoError(t, err)
+	require.Equal(t, 6, chaincodeStub.PutStateCallCount())
+
+	// Existing assets are not overwritten
+	chaincodeStub.GetStateReturnsOnCall(6, []byte(`{"ID":"asset1","owner":"Sam","revision":3}`), nil)
+	err = assetTransfer.InitLedger(transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, 11, chaincodeStub.PutStateCallCount())
+	key, _ := chaincodeStub.PutStateArgsForCall(6)
+	require.Equal(t, "asset2", key)
 
 	chaincodeStub.PutStateReturns(fmt.Errorf("failed inserting key"))
 	err = assetTransfer.InitLedger(transactionContext)
 	require.EqualError(t, err, "failed to put to world state. failed inserting key")
+
+	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
+	err = assetTransfer.InitLedger(transactionContext)
+	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
 func TestCreateAsset(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
 
 	assetTransfer := chaincode.SmartContract{}
-	err := assetTransfer.CreateAsset(transactionContext, "", "", 0, "", 0)
+	err := assetTransfer.CreateAsset(transactionContext, "asset1", "blue", 5, "Tomoko", 300)
 	require.NoError(t, err)
+	requireAssetEvent(t, chaincodeStub, chaincode.AssetCreatedEvent, nil,
+		&chaincode.Asset{ID: "asset1", Color: "blue", Size: 5, Owner: "Tomoko", AppraisedValue: 300, Revision: 1})
+
+	chaincodeStub.SetEventReturns(fmt.Errorf("failed setting event"))
+	err = assetTransfer.CreateAsset(transactionContext, "asset1", "blue", 5, "Tomoko", 300)
+	require.EqualError(t, err, "failed to set AssetCreated event: failed setting event")
+
+	clientIdentity.GetIDReturns("", fmt.Errorf("failed getting client ID"))
+	err = assetTransfer.CreateAsset(transactionContext, "asset1", "blue", 5, "Tomoko", 300)
+	require.EqualError(t, err, "failed to get client identity: failed getting client ID")
 
 	chaincodeStub.GetStateReturns([]byte{}, nil)
 	err = assetTransfer.CreateAsset(transactionContext, "asset1", "", 0, "", 0)
@@ -89,6 +149,9 @@ func TestUpdateAsset(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
 
 	expectedAsset := &chaincode.Asset{ID: "asset1"}
 	bytes, err := json.Marshal(expectedAsset)
@@ -96,8 +159,10 @@ func TestUpdateAsset(t *testing.T) {
 
 	chaincodeStub.GetStateReturns(bytes, nil)
 	assetTransfer := chaincode.SmartContract{}
-	err = assetTransfer.UpdateAsset(transactionContext, "", "", 0, "", 0)
+	err = assetTransfer.UpdateAsset(transactionContext, "asset1", "red", 10, "Brad", 400)
 	require.NoError(t, err)
+	requireAssetEvent(t, chaincodeStub, chaincode.AssetUpdatedEvent, expectedAsset,
+		&chaincode.Asset{ID: "asset1", Color: "red", Size: 10, Owner: "Brad", AppraisedValue: 400, Revision: 1})
 
 	chaincodeStub.GetStateReturns(nil, nil)
 	err = assetTransfer.UpdateAsset(transactionContext, "asset1", "", 0, "", 0)
@@ -112,6 +177,9 @@ func TestDeleteAsset(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
 
 	asset := &chaincode.Asset{ID: "asset1"}
 	bytes, err := json.Marshal(asset)
@@ -120,8 +188,13 @@ func TestDeleteAsset(t *testing.T) {
 	chaincodeStub.GetStateReturns(bytes, nil)
 	chaincodeStub.DelStateReturns(nil)
 	assetTransfer := chaincode.SmartContract{}
-	err = assetTransfer.DeleteAsset(transactionContext, "")
+	err = assetTransfer.DeleteAsset(transactionContext, "asset1")
 	require.NoError(t, err)
+	requireAssetEvent(t, chaincodeStub, chaincode.AssetDeletedEvent, asset, nil)
+
+	chaincodeStub.DelStateReturns(fmt.Errorf("failed deleting key"))
+	err = assetTransfer.DeleteAsset(transactionContext, "asset1")
+	require.EqualError(t, err, "failed to delete from world state. failed deleting key")
 
 	chaincodeStub.GetStateReturns(nil, nil)
 	err = assetTransfer.DeleteAsset(transactionContext, "asset1")
@@ -136,15 +209,19 @@ func TestTransferAsset(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
 
-	asset := &chaincode.Asset{ID: "asset1"}
+	asset := &chaincode.Asset{ID: "asset1", Owner: "Tomoko"}
 	bytes, err := json.Marshal(asset)
 	require.NoError(t, err)
 
 	chaincodeStub.GetStateReturns(bytes, nil)
 	assetTransfer := chaincode.SmartContract{}
-	err = assetTransfer.TransferAsset(transactionContext, "", "")
+	err = assetTransfer.TransferAsset(transactionContext, "asset1", "Max")
 	require.NoError(t, err)
+	requireAssetEvent(t, chaincodeStub, chaincode.AssetTransferredEvent, asset, &chaincode.Asset{ID: "asset1", Owner: "Max", Revision: 1})
 
 	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
 	err = assetTransfer.TransferAsset(transactionContext, "", "")
@@ -182,3 +259,88 @@ func TestGetAllAssets(t *testing.T) {
 	require.EqualError(t, err, "failed retrieving all assets")
 	require.Nil(t, assets)
 }
+
+func TestGetAssetsWithPagination(t *testing.T) {
+	asset1 := &chaincode.Asset{ID: "asset1", Color: "blue", Owner: "Tomoko", AppraisedValue: 300}
+	asset2 := &chaincode.Asset{ID: "asset2", Color: "red", Owner: "Brad", AppraisedValue: 400}
+	bytes1, err := json.Marshal(asset1)
+	require.NoError(t, err)
+	bytes2, err := json.Marshal(asset2)
+	require.NoError(t, err)
+
+	newIterator := func() *mocks.StateQueryIterator {
+		iterator := &mocks.StateQueryIterator{}
+		iterator.HasNextReturnsOnCall(0, true)
+		iterator.HasNextReturnsOnCall(1, true)
+		iterator.HasNextReturnsOnCall(2, false)
+		iterator.NextReturnsOnCall(0, &queryresult.KV{Value: bytes1}, nil)
+		iterator.NextReturnsOnCall(1, &queryresult.KV{Value: bytes2}, nil)
+		return iterator
+	}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: 2, Bookmark: "asset2"}
+
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+
+	chaincodeStub.GetStateByRangeWithPaginationReturns(newIterator(), metadata, nil)
+	assetTransfer := &chaincode.SmartContract{}
+	result, err := assetTransfer.GetAssetsWithPagination(transactionContext, 2, "", "", "", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Asset{asset1, asset2}, result.Records)
+	require.Equal(t, int32(2), result.FetchedRecordsCount)
+	require.Equal(t, "asset2", result.Bookmark)
+
+	startKey, endKey, pageSize, bookmark := chaincodeStub.GetStateByRangeWithPaginationArgsForCall(0)
+	require.Equal(t, "", startKey)
+	require.Equal(t, "", endKey)
+	require.Equal(t, int32(2), pageSize)
+	require.Equal(t, "", bookmark)
+
+	chaincodeStub.GetStateByRangeWithPaginationReturns(newIterator(), metadata, nil)
+	result, err = assetTransfer.GetAssetsWithPagination(transactionContext, 2, "asset0", "Brad", "", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Asset{asset2}, result.Records)
+	require.Equal(t, int32(2), result.FetchedRecordsCount)
+	_, _, _, bookmark = chaincodeStub.GetStateByRangeWithPaginationArgsForCall(1)
+	require.Equal(t, "asset0", bookmark)
+
+	chaincodeStub.GetStateByRangeWithPaginationReturns(newIterator(), metadata, nil)
+	result, err = assetTransfer.GetAssetsWithPagination(transactionContext, 2, "", "", "blue", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Asset{asset1}, result.Records)
+
+	chaincodeStub.GetStateByRangeWithPaginationReturns(newIterator(), metadata, nil)
+	result, err = assetTransfer.GetAssetsWithPagination(transactionContext, 2, "", "", "", 350, 0)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Asset{asset2}, result.Records)
+
+	chaincodeStub.GetStateByRangeWithPaginationReturns(newIterator(), metadata, nil)
+	result, err = assetTransfer.GetAssetsWithPagination(transactionContext, 2, "", "", "", 0, 350)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Asset{asset1}, result.Records)
+
+	chaincodeStub.GetStateByRangeWithPaginationReturns(newIterator(), metadata, nil)
+	result, err = assetTransfer.GetAssetsWithPagination(transactionContext, 2, "", "Tomoko", "red", 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, result.Records)
+
+	_, err = assetTransfer.GetAssetsWithPagination(transactionContext, 0, "", "", "", 0, 0)
+	require.EqualError(t, err, "page size must be positive, got 0")
+
+	_, err = assetTransfer.GetAssetsWithPagination(transactionContext, 2, "", "", "", 500, 400)
+	require.EqualError(t, err, "minimum appraised value 500 is greater than maximum appraised value 400")
+
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturns(true)
+	iterator.NextReturns(nil, fmt.Errorf("failed retrieving next item"))
+	chaincodeStub.GetStateByRangeWithPaginationReturns(iterator, metadata, nil)
+	result, err = assetTransfer.GetAssetsWithPagination(transactionContext, 2, "", "", "", 0, 0)
+	require.EqualError(t, err, "failed retrieving next item")
+	require.Nil(t, result)
+
+	chaincodeStub.GetStateByRangeWithPaginationReturns(nil, nil, fmt.Errorf("failed retrieving assets"))
+	result, err = assetTransfer.GetAssetsWithPagination(transactionContext, 2, "", "", "", 0, 0)
+	require.EqualError(t, err, "failed retrieving assets")
+	require.Nil(t, result)
+}
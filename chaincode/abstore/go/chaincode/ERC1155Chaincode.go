@@ -10,13 +10,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 const uriKey = "uri"
 
 const balancePrefix = "account~tokenId~sender"
 const approvalPrefix = "account~operator"
+const tokenClassPrefix = "tokenClass"
+
+// supplyMigrationKey holds the progress of MigrateTokenSupply
+const supplyMigrationKey = "supplyMigration"
 
 const minterMSPID = "Org1MSP"
 
@@ -95,9 +102,9 @@ type ApprovalForAll struct {
 }
 
 // URI MUST emit when the URI is updated for a token ID.
-// Note: This event is not used in this contract implementation because in this implementation,
-// only the programmatic way of setting URI is used. The URI should contain {id} as part of it
-// and the clients MUST replace this with the actual token ID.
+// In this implementation, SetURI sets a single URI for all token types, so the event is
+// emitted with ID 0. The URI should contain {id} as part of it and the clients MUST replace
+// this with the actual token ID.
 type URI struct {
 	Value string `json:"value"`
 	ID    uint64 `json:"id"`
@@ -110,7 +117,48 @@ type ToID struct {
 	ID uint64
 }
 
+// TokenBalance is the balance of a token type held by an owner, as returned by QueryTokensByOwner.
+// CategoryCode and TokenType are read from the token class of the token type, and are empty
+// for a token type minted before token classes were stored, until it is minted again.
+type TokenBalance struct {
+	TokenID      uint64 `json:"tokenId"`
+	Balance      uint64 `json:"balance"`
+	CategoryCode string `json:"categoryCode"`
+	TokenType    string `json:"tokenType"`
+}
+
+// TokenBalancesQueryResult structure used for returning paginated token balances and metadata
+type TokenBalancesQueryResult struct {
+	Records             []*TokenBalance `json:"records"`
+	FetchedRecordsCount int32           `json:"fetchedRecordsCount"`
+	Bookmark            string          `json:"bookmark"`
+}
+
+// TokenClass describes a token type. It is stored when the token type is first minted, and
+// MintedAmount, the amount minted so far, can never exceed TotalTicket. For a token type minted
+// before token classes were stored, MigrateTokenSupply stores a token class holding only the
+// supply of the token type as MintedAmount, with a TotalTicket of 0, and the next mint of the
+// token type describes it.
+type TokenClass struct {
+	TokenID         uint64 `json:"tokenId"`
+	CategoryCode    string `json:"categoryCode"`
+	PollingResultID string `json:"pollingResultId"`
+	TokenType       string `json:"tokenType"`
+	TotalTicket     uint64 `json:"totalTicket"`
+	MintedAmount    uint64 `json:"mintedAmount"`
+}
+
+// TokenClassesQueryResult structure used for returning paginated token classes and metadata
+type TokenClassesQueryResult struct {
+	Records             []*TokenClass `json:"records"`
+	FetchedRecordsCount int32         `json:"fetchedRecordsCount"`
+	Bookmark            string        `json:"bookmark"`
+}
+
 // Mint creates amount tokens of token type id and assigns them to account.
+// The first mint of a token type stores its token class, and later mints must pass the same
+// categoryCode, pollingResultId, tokenType and totalTicket. The amount minted over all mints of
+// a token type can not exceed totalTicket, even after tokens are burned.
 // This function emits a TransferSingle event.
 // 특정 주소에 새로운 토큰을 발행하고, 해당 이벤트를 TransferSingle로 기록
 func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, tokenId uint64, categoryCode string,
@@ -130,6 +178,13 @@ func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, tokenI
 		return fmt.Errorf("failed to get client id: %v", err)
 	}
 
+	// Record the amount minted in the token class
+	// 토큰 클래스에 발행량 기록
+	err = tokenClassHelper(ctx, TokenClass{tokenId, categoryCode, pollingResultId, tokenType, totalTicket, 0}, amount)
+	if err != nil {
+		return err
+	}
+
 	// Mint tokens
 	// 토큰 발행
 	err = mintHelper(ctx, operator, owner, tokenId, amount)
@@ -143,35 +198,605 @@ func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, tokenI
 	return emitTransferSingle(ctx, transferSingleEvent)
 }
 
-// QueryTokensByOwner retrieves the tokens owned by a specific owner.
-// 특정 소유자의 토큰을 조회하는 기능
-func (s *SmartContract) QueryTokensByOwner(ctx contractapi.TransactionContextInterface, owner string) ([]*TransferSingle, error) {
-	queryString := fmt.Sprintf(`{"selector":{"to":"%s"}}`, owner)
+// SupplyMigrationResult structure used for returning the outcome of a MigrateTokenSupply page
+type SupplyMigrationResult struct {
+	ScannedRecordsCount int      `json:"scannedRecordsCount"`
+	MigratedTokenIDs    []uint64 `json:"migratedTokenIds"`
+	NextStartKey        string   `json:"nextStartKey"`
+}
 
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+// supplyMigration is the progress of MigrateTokenSupply
+type supplyMigration struct {
+	NextStartKey string `json:"nextStartKey"`
+	Completed    bool   `json:"completed"`
+}
+
+// Burn destroys amount tokens of token type id from account.
+// This function triggers a TransferSingle event.
+// 특정 주소의 토큰을 소각하고, 해당 이벤트를 TransferSingle로 기록
+func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, account string, id uint64, amount uint64) error {
+
+	if account == "0x0" {
+		return fmt.Errorf("burn to the zero address")
+	}
+
+	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to burn tokens
+	// 체인코드 권한 확인
+	err := authorizationHelper(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute a query on the world state: %v", err)
+		return err
 	}
-	defer resultsIterator.Close()
 
-	var transferEvents []*TransferSingle
+	// Get ID of submitting client identity
+	// 클라이언트 ID 확인
+	operator, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	// Burn tokens
+	// 토큰 소각
+	err = removeBalance(ctx, account, []uint64{id}, []uint64{amount})
+	if err != nil {
+		return err
+	}
+
+	transferSingleEvent := TransferSingle{operator, account, "0x0", id, amount}
+	return emitTransferSingle(ctx, transferSingleEvent)
+}
+
+// BurnBatch destroys amounts[i] tokens of token type ids[i] from account.
+// This function triggers a TransferBatch event.
+// 특정 주소의 여러 토큰을 한 번에 소각하고, 해당 이벤트를 TransferBatch로 기록
+func (s *SmartContract) BurnBatch(ctx contractapi.TransactionContextInterface, account string, ids []uint64, amounts []uint64) error {
+
+	if account == "0x0" {
+		return fmt.Errorf("burn to the zero address")
+	}
+
+	if len(ids) != len(amounts) {
+		return fmt.Errorf("ids and amounts must have the same length")
+	}
+
+	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to burn tokens
+	// 체인코드 권한 확인
+	err := authorizationHelper(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Get ID of submitting client identity
+	// 클라이언트 ID 확인
+	operator, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	// Burn tokens
+	// 토큰 소각
+	err = removeBalance(ctx, account, ids, amounts)
+	if err != nil {
+		return err
+	}
+
+	transferBatchEvent := TransferBatch{operator, account, "0x0", ids, amounts}
+	return emitTransferBatch(ctx, transferBatchEvent)
+}
+
+// TransferFrom transfers tokens from sender account to recipient account.
+// The caller must be the sender or an operator approved by the sender.
+// This function triggers a TransferSingle event.
+// 보낸 사람의 토큰을 받는 사람에게 전송하고, 해당 이벤트를 TransferSingle로 기록
+func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, sender string, recipient string, id uint64, amount uint64) error {
+
+	if sender == recipient {
+		return fmt.Errorf("transfer to self")
+	}
+
+	if recipient == "0x0" {
+		return fmt.Errorf("transfer to the zero address")
+	}
 
+	// Check whether the caller is the sender or an approved operator
+	// 호출자가 소유자이거나 승인된 운영자인지 확인
+	operator, err := operatorHelper(ctx, sender)
+	if err != nil {
+		return err
+	}
+
+	// Withdraw the funds from the sender address
+	// 보낸 사람의 잔액 차감
+	err = removeBalance(ctx, sender, []uint64{id}, []uint64{amount})
+	if err != nil {
+		return err
+	}
+
+	// Deposit the funds to the recipient address
+	// 받는 사람의 잔액 증가
+	err = addBalance(ctx, sender, recipient, id, amount)
+	if err != nil {
+		return err
+	}
+
+	transferSingleEvent := TransferSingle{operator, sender, recipient, id, amount}
+	return emitTransferSingle(ctx, transferSingleEvent)
+}
+
+// BatchTransferFrom transfers multiple tokens from sender account to recipient account.
+// The caller must be the sender or an operator approved by the sender.
+// This function triggers a TransferBatch event.
+// 보낸 사람의 여러 토큰을 받는 사람에게 한 번에 전송하고, 해당 이벤트를 TransferBatch로 기록
+func (s *SmartContract) BatchTransferFrom(ctx contractapi.TransactionContextInterface, sender string, recipient string, ids []uint64, amounts []uint64) error {
+
+	if sender == recipient {
+		return fmt.Errorf("transfer to self")
+	}
+
+	if recipient == "0x0" {
+		return fmt.Errorf("transfer to the zero address")
+	}
+
+	if len(ids) != len(amounts) {
+		return fmt.Errorf("ids and amounts must have the same length")
+	}
+
+	// Check whether the caller is the sender or an approved operator
+	// 호출자가 소유자이거나 승인된 운영자인지 확인
+	operator, err := operatorHelper(ctx, sender)
+	if err != nil {
+		return err
+	}
+
+	// Withdraw the funds from the sender address
+	// 보낸 사람의 잔액 차감
+	err = removeBalance(ctx, sender, ids, amounts)
+	if err != nil {
+		return err
+	}
+
+	// Deposit the funds to the recipient address
+	// 받는 사람의 잔액 증가
+	recipients := make([]string, len(ids))
+	for i := range recipients {
+		recipients[i] = recipient
+	}
+	err = depositHelper(ctx, sender, recipients, ids, amounts)
+	if err != nil {
+		return err
+	}
+
+	transferBatchEvent := TransferBatch{operator, sender, recipient, ids, amounts}
+	return emitTransferBatch(ctx, transferBatchEvent)
+}
+
+// BatchTransferFromMultiRecipient transfers amounts[i] tokens of token type ids[i] from sender account
+// to recipients[i] account. The caller must be the sender or an operator approved by the sender.
+// This function triggers a TransferBatchMultiRecipient event.
+// 보낸 사람의 여러 토큰을 여러 받는 사람에게 한 번에 전송하고, 해당 이벤트를 TransferBatchMultiRecipient로 기록
+func (s *SmartContract) BatchTransferFromMultiRecipient(ctx contractapi.TransactionContextInterface, sender string, recipients []string, ids []uint64, amounts []uint64) error {
+
+	if len(recipients) != len(ids) || len(ids) != len(amounts) {
+		return fmt.Errorf("recipients, ids, and amounts must have the same length")
+	}
+
+	for _, recipient := range recipients {
+		if sender == recipient {
+			return fmt.Errorf("transfer to self")
+		}
+		if recipient == "0x0" {
+			return fmt.Errorf("transfer to the zero address")
+		}
+	}
+
+	// Check whether the caller is the sender or an approved operator
+	// 호출자가 소유자이거나 승인된 운영자인지 확인
+	operator, err := operatorHelper(ctx, sender)
+	if err != nil {
+		return err
+	}
+
+	// Withdraw the funds from the sender address
+	// 보낸 사람의 잔액 차감
+	err = removeBalance(ctx, sender, ids, amounts)
+	if err != nil {
+		return err
+	}
+
+	// Deposit the funds to the recipient addresses
+	// 받는 사람들의 잔액 증가
+	err = depositHelper(ctx, sender, recipients, ids, amounts)
+	if err != nil {
+		return err
+	}
+
+	transferBatchMultiRecipientEvent := TransferBatchMultiRecipient{operator, sender, recipients, ids, amounts}
+	return emitTransferBatchMultiRecipient(ctx, transferBatchMultiRecipientEvent)
+}
+
+// IsApprovedForAll returns true if account has given approval to operator to manage all of its tokens.
+// 소유자가 운영자에게 모든 토큰의 관리를 승인했는지 조회하는 기능
+func (s *SmartContract) IsApprovedForAll(ctx contractapi.TransactionContextInterface, account string, operator string) (bool, error) {
+	return isApprovedForAllHelper(ctx, account, operator)
+}
+
+// SetApprovalForAll grants or revokes permission to operator to manage all of the caller's tokens.
+// This function triggers an ApprovalForAll event.
+// 호출자의 모든 토큰에 대한 운영자의 관리 권한을 부여하거나 취소하고, 해당 이벤트를 ApprovalForAll로 기록
+func (s *SmartContract) SetApprovalForAll(ctx contractapi.TransactionContextInterface, operator string, approved bool) error {
+
+	// Get ID of submitting client identity
+	// 클라이언트 ID 확인
+	account, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	if account == operator {
+		return fmt.Errorf("setting approval status for self")
+	}
+
+	approvalKey, err := ctx.GetStub().CreateCompositeKey(approvalPrefix, []string{account, operator})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", approvalPrefix, err)
+	}
+
+	approvalJSON, err := json.Marshal(approved)
+	if err != nil {
+		return fmt.Errorf("failed to encode approval JSON of operator %s for account %s: %v", operator, account, err)
+	}
+
+	err = ctx.GetStub().PutState(approvalKey, approvalJSON)
+	if err != nil {
+		return err
+	}
+
+	approvalForAllEvent := ApprovalForAll{account, operator, approved}
+	approvalForAllEventJSON, err := json.Marshal(approvalForAllEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent("ApprovalForAll", approvalForAllEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
+
+// BalanceOf returns the balance of the given token type id held by account.
+// 특정 주소가 보유한 토큰의 잔액을 조회하는 기능
+func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string, id uint64) (uint64, error) {
+	return balanceOfHelper(ctx, account, id)
+}
+
+// BalanceOfBatch returns the balance of multiple account/token pairs.
+// The balance of accounts[i] for token type ids[i] is returned at index i.
+// 여러 주소와 토큰 쌍의 잔액을 한 번에 조회하는 기능
+func (s *SmartContract) BalanceOfBatch(ctx contractapi.TransactionContextInterface, accounts []string, ids []uint64) ([]uint64, error) {
+
+	if len(accounts) != len(ids) {
+		return nil, fmt.Errorf("accounts and ids must have the same length")
+	}
+
+	balances := make([]uint64, len(accounts))
+
+	for i := 0; i < len(accounts); i++ {
+		var err error
+		balances[i], err = balanceOfHelper(ctx, accounts[i], ids[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return balances, nil
+}
+
+// SetURI sets the URI of all token types. The URI must contain {id}, which clients replace
+// with the token ID to obtain the URI of a token type.
+// This function emits a URI event.
+// 모든 토큰 타입의 URI를 설정하는 기능
+func (s *SmartContract) SetURI(ctx contractapi.TransactionContextInterface, uri string) error {
+
+	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to set the URI
+	// 체인코드 권한 확인
+	err := authorizationHelper(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(uri, "{id}") {
+		return fmt.Errorf("failed to set uri, uri should contain '{id}'")
+	}
+
+	err = ctx.GetStub().PutState(uriKey, []byte(uri))
+	if err != nil {
+		return fmt.Errorf("failed to set uri: %v", err)
+	}
+
+	uriEvent := URI{uri, 0}
+	return emitURI(ctx, uriEvent)
+}
+
+// URI returns the URI of token type id, which is shared by all token types and contains {id}.
+// 토큰 타입의 URI를 조회하는 기능
+func (s *SmartContract) URI(ctx contractapi.TransactionContextInterface, id uint64) (string, error) {
+
+	uriBytes, err := ctx.GetStub().GetState(uriKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get uri: %v", err)
+	}
+
+	if uriBytes == nil {
+		return "", fmt.Errorf("no uri is set")
+	}
+
+	return string(uriBytes), nil
+}
+
+// QueryTokensByOwner retrieves a page of the token types held by owner, in the order of their token IDs
+// as strings, with the balance of each token type summed over the senders it was received from.
+// The page holds up to pageSize token types, starting at the bookmark returned with the previous page,
+// or at the first token type when bookmark is empty. The last page is returned with an empty bookmark.
+// 특정 소유자가 보유한 토큰을 토큰 ID별 잔액과 함께 페이지 단위로 조회하는 기능
+func (s *SmartContract) QueryTokensByOwner(ctx contractapi.TransactionContextInterface, owner string, pageSize int32, bookmark string) (*TokenBalancesQueryResult, error) {
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	tokenBalances := []*TokenBalance{}
+	nextBookmark := ""
+
+	// The balance of a token type is stored under one key per sender, and the keys of a token type
+	// are adjacent. Keys are read in pages until a token type beyond the page is found, so that
+	// the keys of a token type are never split between two pages.
+	// 페이지를 넘어서는 토큰 ID가 나올 때까지 잔액 키를 조회
+	for nextBookmark == "" {
+		balanceIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(balancePrefix, []string{owner}, pageSize, bookmark)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get state for prefix %v: %v", balancePrefix, err)
+		}
+
+		for balanceIterator.HasNext() {
+			queryResponse, err := balanceIterator.Next()
+			if err != nil {
+				balanceIterator.Close()
+				return nil, err
+			}
+
+			_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+			if err != nil {
+				balanceIterator.Close()
+				return nil, err
+			}
+			tokenId, err := strconv.ParseUint(compositeKeyParts[1], 10, 64)
+			if err != nil {
+				balanceIterator.Close()
+				return nil, fmt.Errorf("failed to parse token id stored under %s: %v", queryResponse.Key, err)
+			}
+			balAmount, err := strconv.ParseUint(string(queryResponse.Value), 10, 64)
+			if err != nil {
+				balanceIterator.Close()
+				return nil, fmt.Errorf("failed to parse balance stored under %s: %v", queryResponse.Key, err)
+			}
+
+			if len(tokenBalances) == 0 || tokenBalances[len(tokenBalances)-1].TokenID != tokenId {
+				if len(tokenBalances) == int(pageSize) {
+					// The next page starts with this token type
+					nextBookmark = queryResponse.Key
+					break
+				}
+				tokenBalances = append(tokenBalances, &TokenBalance{TokenID: tokenId})
+			}
+
+			tokenBalance := tokenBalances[len(tokenBalances)-1]
+			if balAmount > math.MaxUint64-tokenBalance.Balance {
+				balanceIterator.Close()
+				return nil, fmt.Errorf("balance of account %s for token %v overflows", owner, tokenId)
+			}
+			tokenBalance.Balance += balAmount
+		}
+		balanceIterator.Close()
+
+		if responseMetadata.Bookmark == "" {
+			break
+		}
+		bookmark = responseMetadata.Bookmark
+	}
+
+	// Describe each token type from its token class
+	// 토큰 클래스로 토큰 정보 채우기
+	for _, tokenBalance := range tokenBalances {
+		tokenClass, err := readTokenClass(ctx, tokenBalance.TokenID)
+		if err != nil {
+			return nil, err
+		}
+		if tokenClass != nil {
+			tokenBalance.CategoryCode = tokenClass.CategoryCode
+			tokenBalance.TokenType = tokenClass.TokenType
+		}
+	}
+
+	return &TokenBalancesQueryResult{
+		Records:             tokenBalances,
+		FetchedRecordsCount: int32(len(tokenBalances)),
+		Bookmark:            nextBookmark,
+	}, nil
+}
+
+// GetTokenInfo returns the token class of token type id.
+// 토큰 타입의 클래스 정보를 조회하는 기능
+func (s *SmartContract) GetTokenInfo(ctx contractapi.TransactionContextInterface, tokenId uint64) (*TokenClass, error) {
+
+	tokenClass, err := readTokenClass(ctx, tokenId)
+	if err != nil {
+		return nil, err
+	}
+	if tokenClass == nil {
+		return nil, fmt.Errorf("token %v does not exist", tokenId)
+	}
+
+	return tokenClass, nil
+}
+
+// ListTokenClasses returns a page of the token classes, in the order of their token IDs as strings.
+// The page holds up to pageSize token classes, starting at the bookmark returned with the previous page,
+// or at the first token class when bookmark is empty. The last page is returned with an empty bookmark.
+// 발행된 토큰 클래스 목록을 페이지 단위로 조회하는 기능
+func (s *SmartContract) ListTokenClasses(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*TokenClassesQueryResult, error) {
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(tokenClassPrefix, []string{}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state for prefix %v: %v", tokenClassPrefix, err)
+	}
+	defer resultsIterator.Close()
+
+	tokenClasses := []*TokenClass{}
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get query results: %v", err)
+			return nil, err
 		}
 
-		var transferEvent TransferSingle
-		err = json.Unmarshal(queryResponse.Value, &transferEvent)
+		var tokenClass TokenClass
+		err = json.Unmarshal(queryResponse.Value, &tokenClass)
 		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal TransferSingle event: %v", err)
+			return nil, fmt.Errorf("failed to decode token class stored under %s: %v", queryResponse.Key, err)
 		}
+		tokenClasses = append(tokenClasses, &tokenClass)
+	}
 
-		transferEvents = append(transferEvents, &transferEvent)
+	return &TokenClassesQueryResult{
+		Records:             tokenClasses,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}, nil
+}
+
+// MigrateTokenSupply adds the balances of a page of pageSize balance keys to the token classes of the
+// token types minted before token classes were stored, so that their mints keep counting against
+// the total ticket. Each call resumes after the page of the previous call, and the token types that
+// already have a described token class are skipped, as their supply is recorded by Mint. The token
+// IDs whose supply was added are returned, and the last page with an empty next start key. The
+// migration should run right after the upgrade, before the token types minted earlier are minted again.
+// 토큰 클래스가 없던 시기에 발행된 토큰의 공급량을 토큰 클래스에 기록하는 기능
+func (s *SmartContract) MigrateTokenSupply(ctx contractapi.TransactionContextInterface, pageSize int) (*SupplyMigrationResult, error) {
+
+	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to migrate the supply
+	// 체인코드 권한 확인
+	err := authorizationHelper(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
 	}
 
-	return transferEvents, nil
+	progress := supplyMigration{}
+	progressBytes, err := ctx.GetStub().GetState(supplyMigrationKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the supply migration from world state: %v", err)
+	}
+	if progressBytes != nil {
+		err = json.Unmarshal(progressBytes, &progress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the supply migration: %v", err)
+		}
+	}
+	if progress.Completed {
+		return nil, fmt.Errorf("the token supply is already migrated")
+	}
+
+	// paginated queries are not allowed in update transactions, so the page is bounded by hand:
+	// the balance keys before the start key are skipped
+	balanceIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(balancePrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state for prefix %v: %v", balancePrefix, err)
+	}
+	defer balanceIterator.Close()
+
+	result := &SupplyMigrationResult{MigratedTokenIDs: []uint64{}}
+	supplies := map[uint64]uint64{}
+	for balanceIterator.HasNext() {
+		queryResponse, err := balanceIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if queryResponse.Key < progress.NextStartKey {
+			continue
+		}
+		if result.ScannedRecordsCount == pageSize {
+			result.NextStartKey = queryResponse.Key
+			break
+		}
+		result.ScannedRecordsCount++
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		tokenId, err := strconv.ParseUint(compositeKeyParts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token id stored under %s: %v", queryResponse.Key, err)
+		}
+		balAmount, err := strconv.ParseUint(string(queryResponse.Value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse balance stored under %s: %v", queryResponse.Key, err)
+		}
+
+		supply, ok := supplies[tokenId]
+		if !ok {
+			result.MigratedTokenIDs = append(result.MigratedTokenIDs, tokenId)
+		}
+		if balAmount > math.MaxUint64-supply {
+			return nil, fmt.Errorf("supply of token %v overflows", tokenId)
+		}
+		supplies[tokenId] = supply + balAmount
+	}
+
+	// Writes are not visible to the reads of the transaction, so each token class is updated once
+	// 토큰 클래스별로 한 번만 갱신
+	migratedTokenIds := []uint64{}
+	for _, tokenId := range result.MigratedTokenIDs {
+		tokenClass, err := readTokenClass(ctx, tokenId)
+		if err != nil {
+			return nil, err
+		}
+		if tokenClass == nil {
+			tokenClass = &TokenClass{TokenID: tokenId}
+		} else if tokenClass.TotalTicket != 0 {
+			continue
+		}
+
+		if supplies[tokenId] > math.MaxUint64-tokenClass.MintedAmount {
+			return nil, fmt.Errorf("supply of token %v overflows", tokenId)
+		}
+		tokenClass.MintedAmount += supplies[tokenId]
+		err = putTokenClass(ctx, tokenClass)
+		if err != nil {
+			return nil, err
+		}
+		migratedTokenIds = append(migratedTokenIds, tokenId)
+	}
+	result.MigratedTokenIDs = migratedTokenIds
+
+	progress = supplyMigration{NextStartKey: result.NextStartKey, Completed: result.NextStartKey == ""}
+	progressBytes, err = json.Marshal(progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(supplyMigrationKey, progressBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put the supply migration to world state: %v", err)
+	}
+
+	return result, nil
 }
 
 // Set information for a token and intialize contract.
@@ -242,6 +867,328 @@ func mintHelper(ctx contractapi.TransactionContextInterface, operator string, ow
 	return nil
 }
 
+// tokenClassHelper adds amount to the amount minted of the token class of tokenClass.TokenID, after storing
+// tokenClass as the token class if the token type has none yet, or describing the token class stored
+// by MigrateTokenSupply with tokenClass. Mints of a described token type must describe it as its token
+// class does, and can not exceed its total ticket.
+// 토큰 클래스를 저장하고 최대 발행량을 확인하는 헬퍼 함수
+func tokenClassHelper(ctx contractapi.TransactionContextInterface, tokenClass TokenClass, amount uint64) error {
+
+	storedTokenClass, err := readTokenClass(ctx, tokenClass.TokenID)
+	if err != nil {
+		return err
+	}
+
+	if storedTokenClass == nil || storedTokenClass.TotalTicket == 0 {
+		if tokenClass.TotalTicket == 0 {
+			return fmt.Errorf("total ticket must be a positive integer")
+		}
+		if storedTokenClass != nil {
+			if storedTokenClass.MintedAmount > tokenClass.TotalTicket {
+				return fmt.Errorf("supply %v of token %v already exceeds the total ticket %v", storedTokenClass.MintedAmount, tokenClass.TokenID, tokenClass.TotalTicket)
+			}
+			tokenClass.MintedAmount = storedTokenClass.MintedAmount
+		}
+		storedTokenClass = &tokenClass
+	} else if storedTokenClass.CategoryCode != tokenClass.CategoryCode || storedTokenClass.PollingResultID != tokenClass.PollingResultID ||
+		storedTokenClass.TokenType != tokenClass.TokenType || storedTokenClass.TotalTicket != tokenClass.TotalTicket {
+		return fmt.Errorf("token %v is already minted with a different category code, polling result id, token type or total ticket", tokenClass.TokenID)
+	}
+
+	if amount > storedTokenClass.TotalTicket-storedTokenClass.MintedAmount {
+		return fmt.Errorf("mint exceeds the total ticket of token %v: %v minted, %v to mint, total ticket %v",
+			tokenClass.TokenID, storedTokenClass.MintedAmount, amount, storedTokenClass.TotalTicket)
+	}
+	storedTokenClass.MintedAmount += amount
+
+	return putTokenClass(ctx, storedTokenClass)
+}
+
+// readTokenClass returns the token class of token type tokenId, or nil if the token type has no token class
+func readTokenClass(ctx contractapi.TransactionContextInterface, tokenId uint64) (*TokenClass, error) {
+	tokenClassKey, err := ctx.GetStub().CreateCompositeKey(tokenClassPrefix, []string{strconv.FormatUint(uint64(tokenId), 10)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenClassPrefix, err)
+	}
+
+	tokenClassBytes, err := ctx.GetStub().GetState(tokenClassKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token class of token %v from world state: %v", tokenId, err)
+	}
+	if tokenClassBytes == nil {
+		return nil, nil
+	}
+
+	var tokenClass TokenClass
+	err = json.Unmarshal(tokenClassBytes, &tokenClass)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token class of token %v: %v", tokenId, err)
+	}
+
+	return &tokenClass, nil
+}
+
+func putTokenClass(ctx contractapi.TransactionContextInterface, tokenClass *TokenClass) error {
+	tokenClassKey, err := ctx.GetStub().CreateCompositeKey(tokenClassPrefix, []string{strconv.FormatUint(uint64(tokenClass.TokenID), 10)})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", tokenClassPrefix, err)
+	}
+
+	tokenClassJSON, err := json.Marshal(tokenClass)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	return ctx.GetStub().PutState(tokenClassKey, tokenClassJSON)
+}
+
+// operatorHelper returns the ID of the caller, after checking that the caller is the owner
+// of account or an operator approved by it
+// 호출자가 소유자이거나 승인된 운영자인지 확인하는 헬퍼 함수
+func operatorHelper(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+
+	operator, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	if operator != account {
+		approved, err := isApprovedForAllHelper(ctx, account, operator)
+		if err != nil {
+			return "", err
+		}
+		if !approved {
+			return "", fmt.Errorf("caller is not owner nor is approved")
+		}
+	}
+
+	return operator, nil
+}
+
+func isApprovedForAllHelper(ctx contractapi.TransactionContextInterface, account string, operator string) (bool, error) {
+	approvalKey, err := ctx.GetStub().CreateCompositeKey(approvalPrefix, []string{account, operator})
+	if err != nil {
+		return false, fmt.Errorf("failed to create the composite key for prefix %s: %v", approvalPrefix, err)
+	}
+
+	approvalBytes, err := ctx.GetStub().GetState(approvalKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read approval of operator %s for account %s from world state: %v", operator, account, err)
+	}
+
+	if approvalBytes == nil {
+		return false, nil
+	}
+
+	var approved bool
+	err = json.Unmarshal(approvalBytes, &approved)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode approval JSON of operator %s for account %s: %v", operator, account, err)
+	}
+
+	return approved, nil
+}
+
+// balanceOfHelper sums the balances of the token type id held by account, which are stored
+// under one key per sender that transferred tokens to account
+func balanceOfHelper(ctx contractapi.TransactionContextInterface, account string, id uint64) (uint64, error) {
+
+	if account == "0x0" {
+		return 0, fmt.Errorf("balance query for the zero address")
+	}
+
+	// Convert id to string
+	idString := strconv.FormatUint(uint64(id), 10)
+
+	var balance uint64
+
+	balanceIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(balancePrefix, []string{account, idString})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get state for prefix %v: %v", balancePrefix, err)
+	}
+	defer balanceIterator.Close()
+
+	for balanceIterator.HasNext() {
+		queryResponse, err := balanceIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		balAmount, err := strconv.ParseUint(string(queryResponse.Value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse balance stored under %s: %v", queryResponse.Key, err)
+		}
+		if balAmount > math.MaxUint64-balance {
+			return 0, fmt.Errorf("balance of account %s for token %v overflows", account, id)
+		}
+		balance += balAmount
+	}
+
+	return balance, nil
+}
+
+// removeBalance withdraws amounts[i] tokens of token type ids[i] from sender. The balance of a token
+// type is spread over one key per sender that transferred tokens to the account: keys are consumed
+// in key order until the amount is covered, and the remainder is stored under the key whose sender
+// is the account itself. Nothing is written if the account has insufficient funds, as the transaction
+// then fails.
+// 보낸 사람의 잔액을 차감하는 헬퍼 함수
+func removeBalance(ctx contractapi.TransactionContextInterface, sender string, ids []uint64, amounts []uint64) error {
+
+	// Calculate the total amount of each token to withdraw
+	// 토큰별로 차감할 총량 계산
+	necessaryFunds := make(map[uint64]uint64) // token id -> necessary amount
+	for i := 0; i < len(amounts); i++ {
+		if amounts[i] > math.MaxUint64-necessaryFunds[ids[i]] {
+			return fmt.Errorf("amount of token %v overflows", ids[i])
+		}
+		necessaryFunds[ids[i]] += amounts[i]
+	}
+
+	// Copy the map keys and sort them. This is necessary because iterating maps in Go is not deterministic
+	necessaryFundsKeys := make([]uint64, 0, len(necessaryFunds))
+	for tokenId := range necessaryFunds {
+		necessaryFundsKeys = append(necessaryFundsKeys, tokenId)
+	}
+	sort.Slice(necessaryFundsKeys, func(i, j int) bool { return necessaryFundsKeys[i] < necessaryFundsKeys[j] })
+
+	for _, tokenId := range necessaryFundsKeys {
+		err := removeTokenBalance(ctx, sender, tokenId, necessaryFunds[tokenId])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeTokenBalance withdraws amount tokens of token type tokenId from sender
+func removeTokenBalance(ctx contractapi.TransactionContextInterface, sender string, tokenId uint64, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+
+	// Convert tokenId to string
+	tokenIdString := strconv.FormatUint(uint64(tokenId), 10)
+
+	balanceIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(balancePrefix, []string{sender, tokenIdString})
+	if err != nil {
+		return fmt.Errorf("failed to get state for prefix %v: %v", balancePrefix, err)
+	}
+	defer balanceIterator.Close()
+
+	// Collect the keys storing balances until either the necessary amount is reached or the keys ended.
+	// The key whose sender is the account itself is kept apart, as it receives the remainder.
+	// 필요한 양에 도달할 때까지 잔액 키를 수집
+	var partialBalance uint64
+	var spentKeys []string
+	var selfRecipientKeySpent bool
+	for balanceIterator.HasNext() && partialBalance < amount {
+		queryResponse, err := balanceIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		partBalAmount, err := strconv.ParseUint(string(queryResponse.Value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse balance stored under %s: %v", queryResponse.Key, err)
+		}
+		if partBalAmount > math.MaxUint64-partialBalance {
+			return fmt.Errorf("balance of account %s for token %v overflows", sender, tokenId)
+		}
+		partialBalance += partBalAmount
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return err
+		}
+		if compositeKeyParts[2] == sender {
+			selfRecipientKeySpent = true
+		} else {
+			spentKeys = append(spentKeys, queryResponse.Key)
+		}
+	}
+
+	if partialBalance < amount {
+		return fmt.Errorf("sender has insufficient funds for token %v, needed funds: %v, available fund: %v", tokenId, amount, partialBalance)
+	}
+
+	for _, spentKey := range spentKeys {
+		err = ctx.GetStub().DelState(spentKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete the state of %v: %v", spentKey, err)
+		}
+	}
+
+	// Send the remainder back to the sender. Writes are not visible to reads in the same transaction,
+	// so a spent self recipient key is overwritten rather than added to.
+	// 남은 잔액은 보낸 사람에게 반환
+	remainder := partialBalance - amount
+	if selfRecipientKeySpent {
+		return setBalance(ctx, sender, sender, tokenId, remainder)
+	}
+	if remainder > 0 {
+		return addBalance(ctx, sender, sender, tokenId, remainder)
+	}
+
+	return nil
+}
+
+// depositHelper adds amounts[i] tokens of token type ids[i] to recipients[i] on behalf of sender.
+// Amounts for the same recipient and token type are summed first, as addBalance reads the balance
+// before writing it and does not see earlier writes of the same transaction.
+// 받는 사람들의 잔액을 증가시키는 헬퍼 함수
+func depositHelper(ctx contractapi.TransactionContextInterface, sender string, recipients []string, ids []uint64, amounts []uint64) error {
+
+	deposits := make(map[ToID]uint64) // (recipient, token id) -> amount
+	for i := 0; i < len(recipients); i++ {
+		toID := ToID{recipients[i], ids[i]}
+		if amounts[i] > math.MaxUint64-deposits[toID] {
+			return fmt.Errorf("amount of token %v overflows", ids[i])
+		}
+		deposits[toID] += amounts[i]
+	}
+
+	// Copy the map keys and sort them. This is necessary because iterating maps in Go is not deterministic
+	depositKeys := make([]ToID, 0, len(deposits))
+	for toID := range deposits {
+		depositKeys = append(depositKeys, toID)
+	}
+	sort.Slice(depositKeys, func(i, j int) bool {
+		if depositKeys[i].To != depositKeys[j].To {
+			return depositKeys[i].To < depositKeys[j].To
+		}
+		return depositKeys[i].ID < depositKeys[j].ID
+	})
+
+	for _, toID := range depositKeys {
+		err := addBalance(ctx, sender, toID.To, toID.ID, deposits[toID])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setBalance stores amount as the balance of the token type tokenId sent by sender to recipient,
+// deleting the balance key when amount is zero
+func setBalance(ctx contractapi.TransactionContextInterface, sender string, recipient string, tokenId uint64, amount uint64) error {
+	// Convert tokenId to string
+	tokenIdString := strconv.FormatUint(uint64(tokenId), 10)
+
+	balanceKey, err := ctx.GetStub().CreateCompositeKey(balancePrefix, []string{recipient, tokenIdString, sender})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", balancePrefix, err)
+	}
+
+	if amount == 0 {
+		return ctx.GetStub().DelState(balanceKey)
+	}
+
+	return ctx.GetStub().PutState(balanceKey, []byte(strconv.FormatUint(uint64(amount), 10)))
+}
+
 func addBalance(ctx contractapi.TransactionContextInterface, sender string, recipient string, tokenId uint64, amount uint64) error {
 	// Convert tokenId to string
 	tokenIdString := strconv.FormatUint(uint64(tokenId), 10)
@@ -261,6 +1208,9 @@ func addBalance(ctx contractapi.TransactionContextInterface, sender string, reci
 		balance, _ = strconv.ParseUint(string(balanceBytes), 10, 64)
 	}
 
+	if amount > math.MaxUint64-balance {
+		return fmt.Errorf("balance of account %s for token %v overflows", recipient, tokenId)
+	}
 	balance += amount
 
 	err = ctx.GetStub().PutState(balanceKey, []byte(strconv.FormatUint(uint64(balance), 10)))
@@ -282,3 +1232,42 @@ func emitTransferSingle(ctx contractapi.TransactionContextInterface, transferSin
 	}
 	return nil
 }
+
+func emitTransferBatch(ctx contractapi.TransactionContextInterface, transferBatchEvent TransferBatch) error {
+	transferBatchEventJSON, err := json.Marshal(transferBatchEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent("TransferBatch", transferBatchEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+	return nil
+}
+
+func emitTransferBatchMultiRecipient(ctx contractapi.TransactionContextInterface, transferBatchMultiRecipientEvent TransferBatchMultiRecipient) error {
+	transferBatchMultiRecipientEventJSON, err := json.Marshal(transferBatchMultiRecipientEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent("TransferBatchMultiRecipient", transferBatchMultiRecipientEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+	return nil
+}
+
+func emitURI(ctx contractapi.TransactionContextInterface, uriEvent URI) error {
+	uriEventJSON, err := json.Marshal(uriEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent("URI", uriEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+	return nil
+}
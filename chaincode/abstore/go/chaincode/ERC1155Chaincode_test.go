@@ -0,0 +1,602 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/chaincode/abstore/go/chaincode"
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	minterID = "minter"
+	aliceID  = "alice"
+	bobID    = "bob"
+	carolID  = "carol"
+)
+
+// txTime is the timestamp of every transaction of the tests
+var txTime = time.Unix(1600000000, 0)
+
+// requireLastEvent checks the event of the last committed transaction
+func requireLastEvent(t *testing.T, worldState *mocks.WorldState, name string, payload string) {
+	eventName, eventPayload := worldState.Event()
+	require.Equal(t, name, eventName)
+	require.Equal(t, payload, string(eventPayload))
+}
+
+// mint has the minter mint amount tokens of token type id to owner, in a transaction of its own
+func mint(t *testing.T, token *chaincode.SmartContract, worldState *mocks.WorldState, id uint64, amount uint64, owner string) {
+	err := token.Mint(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), id, "concert", "poll1", "ticket", 1000, amount, owner)
+	require.NoError(t, worldState.Submit(err))
+}
+
+// newToken returns a contract over an in-memory world state, in which alice holds 100 tokens
+// of token type 1 and 50 tokens of token type 2
+func newToken(t *testing.T) (*chaincode.SmartContract, *mocks.WorldState) {
+	worldState := mocks.NewWorldState("tx1", txTime)
+	token := &chaincode.SmartContract{}
+
+	mint(t, token, worldState, 1, 100, aliceID)
+	mint(t, token, worldState, 2, 50, aliceID)
+
+	return token, worldState
+}
+
+// requireBalances checks the balance of each account for each token type
+func requireBalances(t *testing.T, token *chaincode.SmartContract, worldState *mocks.WorldState, balances map[string]map[uint64]uint64) {
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	for account, tokenBalances := range balances {
+		for id, expected := range tokenBalances {
+			balance, err := token.BalanceOf(transactionContext, account, id)
+			require.NoError(t, err)
+			require.Equal(t, expected, balance, "balance of %s for token %d", account, id)
+		}
+	}
+}
+
+func TestBalanceOfBatch(t *testing.T) {
+	token, worldState := newToken(t)
+	transactionContext := mocks.NewTransactionContext(worldState, bobID, "Org2MSP")
+
+	balances, err := token.BalanceOfBatch(transactionContext, []string{aliceID, aliceID, bobID, aliceID}, []uint64{1, 2, 1, 3})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{100, 50, 0, 0}, balances)
+
+	_, err = token.BalanceOfBatch(transactionContext, []string{aliceID, bobID}, []uint64{1})
+	require.EqualError(t, err, "accounts and ids must have the same length")
+	_, err = token.BalanceOf(transactionContext, "0x0", 1)
+	require.EqualError(t, err, "balance query for the zero address")
+}
+
+func TestTransferFrom(t *testing.T) {
+	tests := []struct {
+		name         string
+		callerID     string
+		sender       string
+		recipient    string
+		amount       uint64
+		wantErr      string
+		wantEvent    string
+		wantBalances map[string]map[uint64]uint64
+	}{
+		{
+			name:         "transfer by the owner",
+			callerID:     aliceID,
+			sender:       aliceID,
+			recipient:    bobID,
+			amount:       30,
+			wantEvent:    `{"operator":"alice","from":"alice","to":"bob","id":1,"value":30}`,
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 70, 2: 50}, bobID: {1: 30, 2: 0}},
+		},
+		{
+			name:         "whole balance",
+			callerID:     aliceID,
+			sender:       aliceID,
+			recipient:    bobID,
+			amount:       100,
+			wantEvent:    `{"operator":"alice","from":"alice","to":"bob","id":1,"value":100}`,
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 0, 2: 50}, bobID: {1: 100}},
+		},
+		{
+			name:         "insufficient funds",
+			callerID:     aliceID,
+			sender:       aliceID,
+			recipient:    bobID,
+			amount:       101,
+			wantErr:      "sender has insufficient funds for token 1, needed funds: 101, available fund: 100",
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 100}, bobID: {1: 0}},
+		},
+		{
+			name:      "transfer to self",
+			callerID:  aliceID,
+			sender:    aliceID,
+			recipient: aliceID,
+			amount:    10,
+			wantErr:   "transfer to self",
+		},
+		{
+			name:      "transfer to the zero address",
+			callerID:  aliceID,
+			sender:    aliceID,
+			recipient: "0x0",
+			amount:    10,
+			wantErr:   "transfer to the zero address",
+		},
+		{
+			name:         "caller neither owner nor approved",
+			callerID:     bobID,
+			sender:       aliceID,
+			recipient:    bobID,
+			amount:       10,
+			wantErr:      "caller is not owner nor is approved",
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 100}, bobID: {1: 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+
+			err := token.TransferFrom(mocks.NewTransactionContext(worldState, tt.callerID, "Org2MSP"), tt.sender, tt.recipient, 1, tt.amount)
+			if tt.wantErr != "" {
+				require.EqualError(t, worldState.Submit(err), tt.wantErr)
+			} else {
+				require.NoError(t, worldState.Submit(err))
+				requireLastEvent(t, worldState, "TransferSingle", tt.wantEvent)
+			}
+			requireBalances(t, token, worldState, tt.wantBalances)
+		})
+	}
+}
+
+func TestTransferFromSeveralSenders(t *testing.T) {
+	token, worldState := newToken(t)
+	aliceContext := mocks.NewTransactionContext(worldState, aliceID, "Org2MSP")
+
+	// alice then holds 70 tokens of change from the first transfer, and 10 tokens sent by bob
+	err := token.TransferFrom(aliceContext, aliceID, bobID, 1, 30)
+	require.NoError(t, worldState.Submit(err))
+	err = token.TransferFrom(mocks.NewTransactionContext(worldState, bobID, "Org2MSP"), bobID, aliceID, 1, 10)
+	require.NoError(t, worldState.Submit(err))
+	requireBalances(t, token, worldState, map[string]map[uint64]uint64{aliceID: {1: 80}, bobID: {1: 20}})
+
+	err = token.TransferFrom(aliceContext, aliceID, carolID, 1, 75)
+	require.NoError(t, worldState.Submit(err))
+	requireBalances(t, token, worldState, map[string]map[uint64]uint64{aliceID: {1: 5}, bobID: {1: 20}, carolID: {1: 75}})
+
+	err = token.TransferFrom(aliceContext, aliceID, carolID, 1, 6)
+	require.EqualError(t, worldState.Submit(err), "sender has insufficient funds for token 1, needed funds: 6, available fund: 5")
+}
+
+func TestSetApprovalForAll(t *testing.T) {
+	token, worldState := newToken(t)
+	aliceContext := mocks.NewTransactionContext(worldState, aliceID, "Org2MSP")
+	bobContext := mocks.NewTransactionContext(worldState, bobID, "Org2MSP")
+
+	approved, err := token.IsApprovedForAll(bobContext, aliceID, bobID)
+	require.NoError(t, err)
+	require.False(t, approved)
+
+	err = token.SetApprovalForAll(aliceContext, bobID, true)
+	require.NoError(t, worldState.Submit(err))
+	requireLastEvent(t, worldState, "ApprovalForAll", `{"owner":"alice","operator":"bob","approved":true}`)
+	approved, err = token.IsApprovedForAll(bobContext, aliceID, bobID)
+	require.NoError(t, err)
+	require.True(t, approved)
+
+	// An approved operator transfers on behalf of the owner, and is recorded as the operator
+	err = token.TransferFrom(bobContext, aliceID, carolID, 1, 20)
+	require.NoError(t, worldState.Submit(err))
+	requireLastEvent(t, worldState, "TransferSingle", `{"operator":"bob","from":"alice","to":"carol","id":1,"value":20}`)
+	err = token.BatchTransferFrom(bobContext, aliceID, carolID, []uint64{1, 2}, []uint64{10, 10})
+	require.NoError(t, worldState.Submit(err))
+	requireBalances(t, token, worldState, map[string]map[uint64]uint64{aliceID: {1: 70, 2: 40}, carolID: {1: 30, 2: 10}})
+
+	// The approval only covers the tokens of the account that granted it
+	err = token.TransferFrom(bobContext, carolID, bobID, 1, 10)
+	require.EqualError(t, worldState.Submit(err), "caller is not owner nor is approved")
+
+	err = token.SetApprovalForAll(aliceContext, bobID, false)
+	require.NoError(t, worldState.Submit(err))
+	requireLastEvent(t, worldState, "ApprovalForAll", `{"owner":"alice","operator":"bob","approved":false}`)
+	approved, err = token.IsApprovedForAll(bobContext, aliceID, bobID)
+	require.NoError(t, err)
+	require.False(t, approved)
+	err = token.TransferFrom(bobContext, aliceID, carolID, 1, 20)
+	require.EqualError(t, worldState.Submit(err), "caller is not owner nor is approved")
+
+	err = token.SetApprovalForAll(aliceContext, aliceID, true)
+	require.EqualError(t, worldState.Submit(err), "setting approval status for self")
+}
+
+func TestBatchTransferFrom(t *testing.T) {
+	tests := []struct {
+		name         string
+		recipient    string
+		ids          []uint64
+		amounts      []uint64
+		wantErr      string
+		wantEvent    string
+		wantBalances map[string]map[uint64]uint64
+	}{
+		{
+			name:         "several token types",
+			recipient:    bobID,
+			ids:          []uint64{1, 2},
+			amounts:      []uint64{10, 20},
+			wantEvent:    `{"operator":"alice","from":"alice","to":"bob","ids":[1,2],"values":[10,20]}`,
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 90, 2: 30}, bobID: {1: 10, 2: 20}},
+		},
+		{
+			name:         "same token type twice",
+			recipient:    bobID,
+			ids:          []uint64{1, 2, 1},
+			amounts:      []uint64{10, 20, 5},
+			wantEvent:    `{"operator":"alice","from":"alice","to":"bob","ids":[1,2,1],"values":[10,20,5]}`,
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 85, 2: 30}, bobID: {1: 15, 2: 20}},
+		},
+		{
+			name:         "insufficient funds for one token type",
+			recipient:    bobID,
+			ids:          []uint64{1, 2},
+			amounts:      []uint64{10, 51},
+			wantErr:      "sender has insufficient funds for token 2, needed funds: 51, available fund: 50",
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 100, 2: 50}, bobID: {1: 0, 2: 0}},
+		},
+		{
+			name:      "ids and amounts of different lengths",
+			recipient: bobID,
+			ids:       []uint64{1, 2},
+			amounts:   []uint64{10},
+			wantErr:   "ids and amounts must have the same length",
+		},
+		{
+			name:      "transfer to self",
+			recipient: aliceID,
+			ids:       []uint64{1},
+			amounts:   []uint64{10},
+			wantErr:   "transfer to self",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+
+			err := token.BatchTransferFrom(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), aliceID, tt.recipient, tt.ids, tt.amounts)
+			if tt.wantErr != "" {
+				require.EqualError(t, worldState.Submit(err), tt.wantErr)
+			} else {
+				require.NoError(t, worldState.Submit(err))
+				requireLastEvent(t, worldState, "TransferBatch", tt.wantEvent)
+			}
+			requireBalances(t, token, worldState, tt.wantBalances)
+		})
+	}
+}
+
+func TestBatchTransferFromMultiRecipient(t *testing.T) {
+	tests := []struct {
+		name         string
+		recipients   []string
+		ids          []uint64
+		amounts      []uint64
+		wantErr      string
+		wantEvent    string
+		wantBalances map[string]map[uint64]uint64
+	}{
+		{
+			name:         "several recipients",
+			recipients:   []string{bobID, carolID, bobID},
+			ids:          []uint64{1, 1, 2},
+			amounts:      []uint64{10, 20, 5},
+			wantEvent:    `{"operator":"alice","from":"alice","to":["bob","carol","bob"],"ids":[1,1,2],"values":[10,20,5]}`,
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 70, 2: 45}, bobID: {1: 10, 2: 5}, carolID: {1: 20, 2: 0}},
+		},
+		{
+			name:         "same recipient and token type twice",
+			recipients:   []string{bobID, bobID},
+			ids:          []uint64{1, 1},
+			amounts:      []uint64{10, 20},
+			wantEvent:    `{"operator":"alice","from":"alice","to":["bob","bob"],"ids":[1,1],"values":[10,20]}`,
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 70}, bobID: {1: 30}},
+		},
+		{
+			name:         "insufficient funds",
+			recipients:   []string{bobID, carolID},
+			ids:          []uint64{1, 1},
+			amounts:      []uint64{60, 41},
+			wantErr:      "sender has insufficient funds for token 1, needed funds: 101, available fund: 100",
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 100}, bobID: {1: 0}, carolID: {1: 0}},
+		},
+		{
+			name:       "recipients, ids and amounts of different lengths",
+			recipients: []string{bobID, carolID},
+			ids:        []uint64{1, 1},
+			amounts:    []uint64{10},
+			wantErr:    "recipients, ids, and amounts must have the same length",
+		},
+		{
+			name:       "transfer to the zero address",
+			recipients: []string{bobID, "0x0"},
+			ids:        []uint64{1, 1},
+			amounts:    []uint64{10, 10},
+			wantErr:    "transfer to the zero address",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+
+			err := token.BatchTransferFromMultiRecipient(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), aliceID, tt.recipients, tt.ids, tt.amounts)
+			if tt.wantErr != "" {
+				require.EqualError(t, worldState.Submit(err), tt.wantErr)
+			} else {
+				require.NoError(t, worldState.Submit(err))
+				requireLastEvent(t, worldState, "TransferBatchMultiRecipient", tt.wantEvent)
+			}
+			requireBalances(t, token, worldState, tt.wantBalances)
+		})
+	}
+}
+
+func TestBurn(t *testing.T) {
+	tests := []struct {
+		name         string
+		mspID        string
+		account      string
+		amount       uint64
+		wantErr      string
+		wantEvent    string
+		wantBalances map[string]map[uint64]uint64
+	}{
+		{
+			name:         "part of the balance",
+			mspID:        "Org1MSP",
+			account:      aliceID,
+			amount:       40,
+			wantEvent:    `{"operator":"minter","from":"alice","to":"0x0","id":1,"value":40}`,
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 60, 2: 50}},
+		},
+		{
+			name:         "whole balance",
+			mspID:        "Org1MSP",
+			account:      aliceID,
+			amount:       100,
+			wantEvent:    `{"operator":"minter","from":"alice","to":"0x0","id":1,"value":100}`,
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 0, 2: 50}},
+		},
+		{
+			name:         "insufficient funds",
+			mspID:        "Org1MSP",
+			account:      aliceID,
+			amount:       101,
+			wantErr:      "sender has insufficient funds for token 1, needed funds: 101, available fund: 100",
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 100}},
+		},
+		{
+			name:         "client without burn authorization",
+			mspID:        "Org2MSP",
+			account:      aliceID,
+			amount:       40,
+			wantErr:      "client is not authorized to mint new tokens",
+			wantBalances: map[string]map[uint64]uint64{aliceID: {1: 100}},
+		},
+		{
+			name:    "zero address",
+			mspID:   "Org1MSP",
+			account: "0x0",
+			amount:  40,
+			wantErr: "burn to the zero address",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+
+			err := token.Burn(mocks.NewTransactionContext(worldState, minterID, tt.mspID), tt.account, 1, tt.amount)
+			if tt.wantErr != "" {
+				require.EqualError(t, worldState.Submit(err), tt.wantErr)
+			} else {
+				require.NoError(t, worldState.Submit(err))
+				requireLastEvent(t, worldState, "TransferSingle", tt.wantEvent)
+			}
+			requireBalances(t, token, worldState, tt.wantBalances)
+		})
+	}
+}
+
+func TestBurnBatch(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	err := token.BurnBatch(minterContext, aliceID, []uint64{1, 2, 1}, []uint64{10, 50, 5})
+	require.NoError(t, worldState.Submit(err))
+	requireLastEvent(t, worldState, "TransferBatch", `{"operator":"minter","from":"alice","to":"0x0","ids":[1,2,1],"values":[10,50,5]}`)
+	requireBalances(t, token, worldState, map[string]map[uint64]uint64{aliceID: {1: 85, 2: 0}})
+
+	err = token.BurnBatch(minterContext, aliceID, []uint64{1, 2}, []uint64{10, 1})
+	require.EqualError(t, worldState.Submit(err), "sender has insufficient funds for token 2, needed funds: 1, available fund: 0")
+	requireBalances(t, token, worldState, map[string]map[uint64]uint64{aliceID: {1: 85, 2: 0}})
+
+	err = token.BurnBatch(minterContext, aliceID, []uint64{1, 2}, []uint64{10})
+	require.EqualError(t, worldState.Submit(err), "ids and amounts must have the same length")
+	err = token.BurnBatch(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), aliceID, []uint64{1}, []uint64{10})
+	require.EqualError(t, worldState.Submit(err), "client is not authorized to mint new tokens")
+}
+
+func TestSetURI(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	_, err := token.URI(minterContext, 1)
+	require.EqualError(t, err, "no uri is set")
+
+	err = token.SetURI(minterContext, "https://example.com/tokens/{id}.json")
+	require.NoError(t, worldState.Submit(err))
+	requireLastEvent(t, worldState, "URI", `{"value":"https://example.com/tokens/{id}.json","id":0}`)
+	uri, err := token.URI(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), 1)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/tokens/{id}.json", uri)
+
+	err = token.SetURI(minterContext, "https://example.com/tokens.json")
+	require.EqualError(t, worldState.Submit(err), "failed to set uri, uri should contain '{id}'")
+	err = token.SetURI(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), "https://example.com/other/{id}.json")
+	require.EqualError(t, worldState.Submit(err), "client is not authorized to mint new tokens")
+	uri, err = token.URI(minterContext, 1)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/tokens/{id}.json", uri)
+}
+
+func TestQueryTokensByOwner(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	// alice holds token type 1 under two keys, one per sender
+	mint(t, token, worldState, 1, 10, bobID)
+	err := token.TransferFrom(mocks.NewTransactionContext(worldState, bobID, "Org2MSP"), bobID, aliceID, 1, 10)
+	require.NoError(t, worldState.Submit(err))
+	mint(t, token, worldState, 10, 5, aliceID)
+	mint(t, token, worldState, 3, 7, aliceID)
+
+	// Token types are returned in the order of their token IDs as strings
+	result, err := token.QueryTokensByOwner(minterContext, aliceID, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenBalancesQueryResult{
+		Records: []*chaincode.TokenBalance{
+			{TokenID: 1, Balance: 110, CategoryCode: "concert", TokenType: "ticket"},
+			{TokenID: 10, Balance: 5, CategoryCode: "concert", TokenType: "ticket"},
+		},
+		FetchedRecordsCount: 2,
+		Bookmark:            "\x00account~tokenId~sender\x00alice\x002\x00minter\x00",
+	}, result)
+
+	result, err = token.QueryTokensByOwner(minterContext, aliceID, 2, result.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenBalancesQueryResult{
+		Records: []*chaincode.TokenBalance{
+			{TokenID: 2, Balance: 50, CategoryCode: "concert", TokenType: "ticket"},
+			{TokenID: 3, Balance: 7, CategoryCode: "concert", TokenType: "ticket"},
+		},
+		FetchedRecordsCount: 2,
+	}, result)
+
+	result, err = token.QueryTokensByOwner(minterContext, bobID, 2, "")
+	require.NoError(t, err)
+	require.Empty(t, result.Records)
+	require.Equal(t, "", result.Bookmark)
+
+	_, err = token.QueryTokensByOwner(minterContext, aliceID, 0, "")
+	require.EqualError(t, err, "page size must be a positive integer")
+}
+
+func TestMintTokenClass(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	tokenClass, err := token.GetTokenInfo(minterContext, 1)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenClass{TokenID: 1, CategoryCode: "concert", PollingResultID: "poll1", TokenType: "ticket", TotalTicket: 1000, MintedAmount: 100}, tokenClass)
+
+	err = token.Mint(minterContext, 1, "concert", "poll1", "ticket", 1000, 900, bobID)
+	require.NoError(t, worldState.Submit(err))
+	err = token.Mint(minterContext, 1, "concert", "poll1", "ticket", 1000, 1, bobID)
+	require.EqualError(t, worldState.Submit(err), "mint exceeds the total ticket of token 1: 1000 minted, 1 to mint, total ticket 1000")
+	err = token.Mint(minterContext, 2, "concert", "poll1", "ticket", 2000, 1, bobID)
+	require.EqualError(t, worldState.Submit(err), "token 2 is already minted with a different category code, polling result id, token type or total ticket")
+	err = token.Mint(minterContext, 3, "concert", "poll1", "ticket", 0, 1, bobID)
+	require.EqualError(t, worldState.Submit(err), "total ticket must be a positive integer")
+
+	// Burned tokens still count against the total ticket
+	err = token.Burn(minterContext, bobID, 1, 100)
+	require.NoError(t, worldState.Submit(err))
+	err = token.Mint(minterContext, 1, "concert", "poll1", "ticket", 1000, 1, bobID)
+	require.EqualError(t, worldState.Submit(err), "mint exceeds the total ticket of token 1: 1000 minted, 1 to mint, total ticket 1000")
+
+	_, err = token.GetTokenInfo(minterContext, 3)
+	require.EqualError(t, err, "token 3 does not exist")
+}
+
+func TestListTokenClasses(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	mint(t, token, worldState, 10, 5, bobID)
+
+	// Token classes are returned in the order of their token IDs as strings
+	result, err := token.ListTokenClasses(minterContext, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenClassesQueryResult{
+		Records: []*chaincode.TokenClass{
+			{TokenID: 1, CategoryCode: "concert", PollingResultID: "poll1", TokenType: "ticket", TotalTicket: 1000, MintedAmount: 100},
+			{TokenID: 10, CategoryCode: "concert", PollingResultID: "poll1", TokenType: "ticket", TotalTicket: 1000, MintedAmount: 5},
+		},
+		FetchedRecordsCount: 2,
+		Bookmark:            "\x00tokenClass\x002\x00",
+	}, result)
+
+	result, err = token.ListTokenClasses(minterContext, 2, result.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenClassesQueryResult{
+		Records:             []*chaincode.TokenClass{{TokenID: 2, CategoryCode: "concert", PollingResultID: "poll1", TokenType: "ticket", TotalTicket: 1000, MintedAmount: 50}},
+		FetchedRecordsCount: 1,
+	}, result)
+
+	_, err = token.ListTokenClasses(minterContext, 0, "")
+	require.EqualError(t, err, "page size must be a positive integer")
+}
+
+func TestMintTokenClassOfLegacyToken(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	// Token type 7 was minted before token classes were stored: only its balances exist
+	require.NoError(t, worldState.PutState("\x00account~tokenId~sender\x00alice\x007\x00minter\x00", []byte("30")))
+	require.NoError(t, worldState.PutState("\x00account~tokenId~sender\x00bob\x007\x00alice\x00", []byte("15")))
+	worldState.Commit()
+
+	balances, err := token.QueryTokensByOwner(minterContext, bobID, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.TokenBalance{{TokenID: 7, Balance: 15}}, balances.Records)
+
+	_, err = token.MigrateTokenSupply(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), 2)
+	require.EqualError(t, worldState.Submit(err), "client is not authorized to mint new tokens")
+	_, err = token.MigrateTokenSupply(minterContext, 0)
+	require.EqualError(t, worldState.Submit(err), "page size must be a positive integer")
+
+	// The balances of token types 1 and 2 are recorded by their token classes already
+	result, err := token.MigrateTokenSupply(minterContext, 3)
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, &chaincode.SupplyMigrationResult{
+		ScannedRecordsCount: 3,
+		MigratedTokenIDs:    []uint64{7},
+		NextStartKey:        "\x00account~tokenId~sender\x00bob\x007\x00alice\x00",
+	}, result)
+	tokenClass, err := token.GetTokenInfo(minterContext, 7)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenClass{TokenID: 7, MintedAmount: 30}, tokenClass)
+
+	result, err = token.MigrateTokenSupply(minterContext, 3)
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, &chaincode.SupplyMigrationResult{ScannedRecordsCount: 1, MigratedTokenIDs: []uint64{7}}, result)
+	tokenClass, err = token.GetTokenInfo(minterContext, 7)
+	require.NoError(t, err)
+	require.Equal(t, uint64(45), tokenClass.MintedAmount)
+
+	_, err = token.MigrateTokenSupply(minterContext, 3)
+	require.EqualError(t, worldState.Submit(err), "the token supply is already migrated")
+
+	err = token.Mint(minterContext, 7, "concert", "poll7", "ticket", 40, 1, bobID)
+	require.EqualError(t, worldState.Submit(err), "supply 45 of token 7 already exceeds the total ticket 40")
+
+	err = token.Mint(minterContext, 7, "concert", "poll7", "ticket", 50, 5, bobID)
+	require.NoError(t, worldState.Submit(err))
+	tokenClass, err = token.GetTokenInfo(minterContext, 7)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.TokenClass{TokenID: 7, CategoryCode: "concert", PollingResultID: "poll7", TokenType: "ticket", TotalTicket: 50, MintedAmount: 50}, tokenClass)
+	err = token.Mint(minterContext, 7, "concert", "poll7", "ticket", 50, 1, bobID)
+	require.EqualError(t, worldState.Submit(err), "mint exceeds the total ticket of token 7: 50 minted, 1 to mint, total ticket 50")
+
+	balances, err = token.QueryTokensByOwner(minterContext, bobID, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.TokenBalance{{TokenID: 7, Balance: 20, CategoryCode: "concert", TokenType: "ticket"}}, balances.Records)
+}
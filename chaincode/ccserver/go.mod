@@ -0,0 +1,12 @@
+module github.com/hyperledger/fabric-samples/chaincode/ccserver
+
+go 1.13
+
+require (
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20200424173110-d7076418f212
+	github.com/hyperledger/fabric-contract-api-go v1.1.0
+	github.com/hyperledger/fabric-protos-go v0.0.0-20200424173316-dd554ba3746e
+	github.com/prometheus/client_golang v1.7.1
+	github.com/stretchr/testify v1.5.1
+	google.golang.org/grpc v1.23.0
+)
@@ -0,0 +1,280 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package metrics records Prometheus metrics of the transactions of a chaincode, by transaction function.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// metricsNamespace prefixes the name of every metric exported by the chaincode server
+	metricsNamespace = "chaincode"
+	// metadataFunction is the function of the contract API system contract returning the
+	// metadata of the contracts of the chaincode
+	metadataFunction = "org.hyperledger.fabric:GetMetadata"
+	// noFunction labels the invocations made without a function name, such as the chaincode init
+	noFunction = "<none>"
+	// unknownFunction labels the invocations of functions the chaincode does not define, so that
+	// clients can not create new label values, and time series, at will
+	unknownFunction = "unknown"
+)
+
+// Metrics holds the per transaction function metrics of the chaincode server
+type Metrics struct {
+	registry    *prometheus.Registry
+	invocations *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	reads       *prometheus.CounterVec
+	writes      *prometheus.CounterVec
+}
+
+// New registers the chaincode metrics, along with the Go runtime
+// and process metrics, on a dedicated registry
+func New() *Metrics {
+	labels := []string{"function"}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		invocations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "transactions_total",
+			Help:      "Number of transactions invoked, by transaction function.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "transaction_errors_total",
+			Help:      "Number of transactions that returned an error, by transaction function.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "transaction_duration_seconds",
+			Help:      "Time taken to execute transactions, by transaction function.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		reads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "world_state_reads_total",
+			Help:      "Number of world state reads and queries, by transaction function.",
+		}, labels),
+		writes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "world_state_writes_total",
+			Help:      "Number of world state writes and deletes, by transaction function.",
+		}, labels),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.invocations,
+		m.errors,
+		m.duration,
+		m.reads,
+		m.writes,
+	)
+
+	return m
+}
+
+// Handler returns the HTTP handler exposing the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Instrument wraps the chaincode so that every invocation is recorded in the metrics.
+// Invocations are labelled with the transaction functions registered by the chaincode,
+// which are read from its contract API metadata. When the metadata can not be read, every
+// invocation with a function name is labelled as unknown.
+func (m *Metrics) Instrument(chaincode shim.Chaincode) shim.Chaincode {
+	functions, err := registeredFunctions(chaincode)
+	if err != nil {
+		log.Printf("Transaction functions are labelled as %s in metrics: %v", unknownFunction, err)
+	}
+
+	return &instrumentedChaincode{chaincode: chaincode, metrics: m, functions: functions}
+}
+
+// registeredFunctions returns the transaction functions of the chaincode, as named by
+// its clients: prefixed with the contract name, and without prefix for the default contract
+func registeredFunctions(chaincode shim.Chaincode) (map[string]bool, error) {
+	stub := shimtest.NewMockStub("metadata", chaincode)
+	response := stub.MockInvoke("metadata", [][]byte{[]byte(metadataFunction)})
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return nil, fmt.Errorf("failed to read the chaincode metadata: %s", response.Message)
+	}
+
+	var metadata struct {
+		Contracts map[string]struct {
+			Name         string `json:"name"`
+			Default      bool   `json:"default"`
+			Transactions []struct {
+				Name string `json:"name"`
+			} `json:"transactions"`
+		} `json:"contracts"`
+	}
+	err := json.Unmarshal(response.Payload, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the chaincode metadata: %v", err)
+	}
+
+	functions := map[string]bool{}
+	for _, contract := range metadata.Contracts {
+		for _, transaction := range contract.Transactions {
+			functions[contract.Name+":"+transaction.Name] = true
+			if contract.Default {
+				functions[transaction.Name] = true
+			}
+		}
+	}
+
+	return functions, nil
+}
+
+// instrumentedChaincode records the metrics of the transactions of the wrapped chaincode.
+// Errors are read from the response status, as the contract API does not call the
+// after transaction hook of failed transactions.
+type instrumentedChaincode struct {
+	chaincode shim.Chaincode
+	metrics   *Metrics
+	functions map[string]bool
+}
+
+// Init records the metrics of the chaincode init function
+func (c *instrumentedChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return c.observe(stub, c.chaincode.Init)
+}
+
+// Invoke records the metrics of the invoked transaction function
+func (c *instrumentedChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	return c.observe(stub, c.chaincode.Invoke)
+}
+
+// observe runs the transaction with a stub counting its world state accesses
+func (c *instrumentedChaincode) observe(stub shim.ChaincodeStubInterface, run func(shim.ChaincodeStubInterface) peer.Response) peer.Response {
+	function := c.functionLabel(stub)
+
+	start := time.Now()
+	response := run(&countingStub{
+		ChaincodeStubInterface: stub,
+		reads:                  c.metrics.reads.WithLabelValues(function),
+		writes:                 c.metrics.writes.WithLabelValues(function),
+	})
+
+	c.metrics.duration.WithLabelValues(function).Observe(time.Since(start).Seconds())
+	c.metrics.invocations.WithLabelValues(function).Inc()
+	if response.Status >= shim.ERRORTHRESHOLD {
+		c.metrics.errors.WithLabelValues(function).Inc()
+	}
+
+	return response
+}
+
+// functionLabel returns the label of the invoked function: its name when the chaincode registers
+// it, with the first letter upper-cased as done by the contract API, and unknown otherwise
+func (c *instrumentedChaincode) functionLabel(stub shim.ChaincodeStubInterface) string {
+	function, _ := stub.GetFunctionAndParameters()
+	if function == "" {
+		return noFunction
+	}
+
+	prefix := ""
+	name := function
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		prefix, name = function[:i+1], function[i+1:]
+	}
+	if name == "" {
+		return unknownFunction
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	function = prefix + string(runes)
+
+	if !c.functions[function] {
+		return unknownFunction
+	}
+
+	return function
+}
+
+// countingStub counts the world state reads and writes made through the wrapped stub.
+// Each query counts as a single read, however many records it returns.
+type countingStub struct {
+	shim.ChaincodeStubInterface
+	reads  prometheus.Counter
+	writes prometheus.Counter
+}
+
+// GetState counts a read
+func (s *countingStub) GetState(key string) ([]byte, error) {
+	s.reads.Inc()
+	return s.ChaincodeStubInterface.GetState(key)
+}
+
+// GetStateByRange counts a read
+func (s *countingStub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	s.reads.Inc()
+	return s.ChaincodeStubInterface.GetStateByRange(startKey, endKey)
+}
+
+// GetStateByRangeWithPagination counts a read
+func (s *countingStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	s.reads.Inc()
+	return s.ChaincodeStubInterface.GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+}
+
+// GetStateByPartialCompositeKey counts a read
+func (s *countingStub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	s.reads.Inc()
+	return s.ChaincodeStubInterface.GetStateByPartialCompositeKey(objectType, keys)
+}
+
+// GetStateByPartialCompositeKeyWithPagination counts a read
+func (s *countingStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	s.reads.Inc()
+	return s.ChaincodeStubInterface.GetStateByPartialCompositeKeyWithPagination(objectType, keys, pageSize, bookmark)
+}
+
+// GetQueryResult counts a read
+func (s *countingStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	s.reads.Inc()
+	return s.ChaincodeStubInterface.GetQueryResult(query)
+}
+
+// GetQueryResultWithPagination counts a read
+func (s *countingStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	s.reads.Inc()
+	return s.ChaincodeStubInterface.GetQueryResultWithPagination(query, pageSize, bookmark)
+}
+
+// GetHistoryForKey counts a read
+func (s *countingStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	s.reads.Inc()
+	return s.ChaincodeStubInterface.GetHistoryForKey(key)
+}
+
+// PutState counts a write
+func (s *countingStub) PutState(key string, value []byte) error {
+	s.writes.Inc()
+	return s.ChaincodeStubInterface.PutState(key, value)
+}
+
+// DelState counts a write
+func (s *countingStub) DelState(key string) error {
+	s.writes.Inc()
+	return s.ChaincodeStubInterface.DelState(key)
+}
@@ -0,0 +1,84 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleContract is the default contract of the test chaincode
+type sampleContract struct {
+	contractapi.Contract
+}
+
+// ReadValue is a transaction function of the test chaincode
+func (c *sampleContract) ReadValue(ctx contractapi.TransactionContextInterface, key string) (string, error) {
+	value, err := ctx.GetStub().GetState(key)
+	return string(value), err
+}
+
+func TestFunctionLabel(t *testing.T) {
+	contract := &sampleContract{}
+	contract.Name = "sample"
+	chaincode, err := contractapi.NewChaincode(contract)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		function  string
+		wantLabel string
+	}{
+		{name: "default contract function", function: "ReadValue", wantLabel: "ReadValue"},
+		{name: "lower-cased function", function: "readValue", wantLabel: "ReadValue"},
+		{name: "function prefixed with the contract name", function: "sample:ReadValue", wantLabel: "sample:ReadValue"},
+		{name: "system contract function", function: metadataFunction, wantLabel: metadataFunction},
+		{name: "unknown function", function: "DropTables", wantLabel: unknownFunction},
+		{name: "unknown contract", function: "other:ReadValue", wantLabel: unknownFunction},
+		{name: "blank function of a contract", function: "sample:", wantLabel: unknownFunction},
+		{name: "no function", function: "", wantLabel: noFunction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := New()
+			stub := shimtest.NewMockStub("sample", metrics.Instrument(chaincode))
+
+			stub.MockInvoke("tx1", [][]byte{[]byte(tt.function), []byte("key")})
+
+			require.Equal(t, 1, testutil.CollectAndCount(metrics.invocations))
+			require.Equal(t, float64(1), testutil.ToFloat64(metrics.invocations.WithLabelValues(tt.wantLabel)))
+		})
+	}
+}
+
+// plainChaincode is a chaincode written without the contract API, which has no metadata
+type plainChaincode struct{}
+
+// Init succeeds
+func (c *plainChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+// Invoke succeeds whatever the function
+func (c *plainChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+func TestFunctionLabelWithoutMetadata(t *testing.T) {
+	metrics := New()
+	stub := shimtest.NewMockStub("plain", metrics.Instrument(&plainChaincode{}))
+
+	stub.MockInvoke("tx1", [][]byte{[]byte("ReadValue")})
+	stub.MockInvoke("tx2", [][]byte{[]byte("")})
+
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.invocations.WithLabelValues(unknownFunction)))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.invocations.WithLabelValues(noFunction)))
+}
@@ -0,0 +1,303 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package ccserver runs a chaincode as an external service, along with optional
+// health probes and Prometheus metrics endpoints.
+package ccserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-samples/chaincode/ccserver/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// shutdownTimeout bounds the time given to the servers to finish in-flight requests
+	shutdownTimeout = 5 * time.Second
+	// maxMessageSize matches the message size limit of the peer and of the shim chaincode server
+	maxMessageSize = 100 * 1024 * 1024
+)
+
+// Config holds the chaincode server settings, read from the environment by LoadConfig
+type Config struct {
+	CCID    string
+	Address string
+	// TLSKeyFile and TLSCertFile enable TLS on the chaincode server when both are set
+	TLSKeyFile  string
+	TLSCertFile string
+	// TLSClientCAFile enables mutual TLS: peers must present a certificate issued by this CA
+	TLSClientCAFile string
+	// HealthAddress is the listen address of the HTTP liveness and readiness endpoints,
+	// which are disabled when it is not set
+	HealthAddress string
+	// MetricsAddress is the listen address of the Prometheus metrics endpoint,
+	// which is disabled when it is not set
+	MetricsAddress string
+}
+
+// LoadConfig reads the chaincode server settings from the environment
+func LoadConfig() Config {
+	return Config{
+		CCID:            os.Getenv("CHAINCODE_ID"),
+		Address:         os.Getenv("CHAINCODE_SERVER_ADDRESS"),
+		TLSKeyFile:      os.Getenv("CHAINCODE_TLS_KEY_FILE"),
+		TLSCertFile:     os.Getenv("CHAINCODE_TLS_CERT_FILE"),
+		TLSClientCAFile: os.Getenv("CHAINCODE_TLS_CLIENT_CA_FILE"),
+		HealthAddress:   os.Getenv("CHAINCODE_HEALTH_ADDRESS"),
+		MetricsAddress:  os.Getenv("CHAINCODE_METRICS_ADDRESS"),
+	}
+}
+
+// tlsConfig loads the TLS material referenced by the configuration. It returns nil
+// when TLS is disabled.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if c.TLSKeyFile == "" && c.TLSCertFile == "" {
+		if c.TLSClientCAFile != "" {
+			return nil, fmt.Errorf("CHAINCODE_TLS_CLIENT_CA_FILE requires CHAINCODE_TLS_KEY_FILE and CHAINCODE_TLS_CERT_FILE")
+		}
+		return nil, nil
+	}
+	if c.TLSKeyFile == "" || c.TLSCertFile == "" {
+		return nil, fmt.Errorf("CHAINCODE_TLS_KEY_FILE and CHAINCODE_TLS_CERT_FILE must be set together")
+	}
+
+	key, err := ioutil.ReadFile(filepath.Clean(c.TLSKeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS key: %v", err)
+	}
+	cert, err := ioutil.ReadFile(filepath.Clean(c.TLSCertFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS certificate: %v", err)
+	}
+	keyPair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TLS key pair: %v", err)
+	}
+
+	// follow the defaults of the peer and of the shim chaincode server
+	tlsConfig := &tls.Config{
+		MinVersion:             tls.VersionTLS12,
+		Certificates:           []tls.Certificate{keyPair},
+		SessionTicketsDisabled: true,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+		},
+	}
+
+	if c.TLSClientCAFile != "" {
+		clientCACerts, err := ioutil.ReadFile(filepath.Clean(c.TLSClientCAFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS client CA certificates: %v", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(clientCACerts) {
+			return nil, fmt.Errorf("failed to parse TLS client CA certificates")
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// newGRPCServer returns the gRPC server of the chaincode, with the keepalive and message
+// size settings of the shim chaincode server
+func (c Config) newGRPCServer(chaincode shim.Chaincode) (*grpc.Server, error) {
+	if c.CCID == "" {
+		return nil, errors.New("CHAINCODE_ID must be set")
+	}
+	if c.Address == "" {
+		return nil, errors.New("CHAINCODE_SERVER_ADDRESS must be set")
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    1 * time.Minute,
+			Timeout: 20 * time.Second,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             1 * time.Minute,
+			PermitWithoutStream: true,
+		}),
+		grpc.MaxSendMsgSize(maxMessageSize),
+		grpc.MaxRecvMsgSize(maxMessageSize),
+		grpc.ConnectionTimeout(5 * time.Second),
+	}
+	if tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(serverOpts...)
+	// the shim chaincode server only provides the handler of the peer connections here,
+	// so that the listener and the lifetime of the gRPC server stay under our control
+	peer.RegisterChaincodeServer(server, &shim.ChaincodeServer{CCID: c.CCID, CC: chaincode})
+
+	return server, nil
+}
+
+// healthServer serves the liveness probe on /healthz and the readiness probe on /readyz
+type healthServer struct {
+	ready  int32
+	server *http.Server
+}
+
+// newHealthServer returns a health server listening on the given address
+func newHealthServer(address string) *healthServer {
+	h := &healthServer{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&h.ready) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	h.server = &http.Server{Addr: address, Handler: mux}
+
+	return h
+}
+
+// setReady changes the outcome of the readiness probe
+func (h *healthServer) setReady(ready bool) {
+	var value int32
+	if ready {
+		value = 1
+	}
+	atomic.StoreInt32(&h.ready, value)
+}
+
+// Run serves the chaincode until a server fails or the process receives SIGTERM or SIGINT.
+// The readiness probe succeeds once the chaincode server listens on its address. On a signal
+// the readiness probe fails first, then the chaincode server stops accepting connections and
+// closes the connections of the peers after shutdownTimeout, the HTTP servers are stopped,
+// and Run returns nil so that the process can exit cleanly.
+func Run(config Config, chaincode shim.Chaincode) error {
+	var chaincodeMetrics *metrics.Metrics
+	if config.MetricsAddress != "" {
+		chaincodeMetrics = metrics.New()
+		chaincode = chaincodeMetrics.Instrument(chaincode)
+	}
+
+	server, err := config.newGRPCServer(chaincode)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", config.Address, err)
+	}
+
+	errs := make(chan error, 3)
+	var httpServers []*http.Server
+
+	if chaincodeMetrics != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", chaincodeMetrics.Handler())
+		metricsServer := &http.Server{Addr: config.MetricsAddress, Handler: mux}
+		httpServers = append(httpServers, metricsServer)
+		go serveHTTP(metricsServer, "metrics", errs)
+	}
+
+	var health *healthServer
+	if config.HealthAddress != "" {
+		health = newHealthServer(config.HealthAddress)
+		httpServers = append(httpServers, health.server)
+		go serveHTTP(health.server, "health", errs)
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			errs <- fmt.Errorf("chaincode server failed: %v", err)
+		}
+	}()
+	// connections are accepted from the bound listener from now on
+	if health != nil {
+		health.setReady(true)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-errs:
+		server.Stop()
+		return err
+	case sig := <-signals:
+		log.Printf("Received %s, shutting down", sig)
+	}
+
+	if health != nil {
+		health.setReady(false)
+	}
+
+	stopGRPC(server, shutdownTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, httpServer := range httpServers {
+		if err := httpServer.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down HTTP server %s: %v", httpServer.Addr, err)
+		}
+	}
+
+	return nil
+}
+
+// stopGRPC stops the gRPC server gracefully, closing the connections still open after
+// the timeout. Peers keep their chaincode stream open, so a graceful stop alone would
+// wait for them to disconnect.
+func stopGRPC(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		server.Stop()
+	}
+}
+
+// serveHTTP runs the HTTP server, reporting on errs any failure other than a shutdown
+func serveHTTP(server *http.Server, name string, errs chan<- error) {
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		errs <- fmt.Errorf("%s server failed: %v", name, err)
+	}
+}
@@ -7,18 +7,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"strconv"
 
-	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-samples/chaincode/ccserver"
 )
 
-type ServerConfig struct {
-	CCID    string
-	Address string
-}
-
 // SmartContract provides functions for managing a car
 type SmartContract struct {
 	contractapi.Contract
@@ -145,10 +139,7 @@ func (s *SmartContract) ChangeCarOwner(ctx contractapi.TransactionContextInterfa
 
 func main() {
 	// See chaincode.env.example
-	config := ServerConfig{
-		CCID:    os.Getenv("CHAINCODE_ID"),
-		Address: os.Getenv("CHAINCODE_SERVER_ADDRESS"),
-	}
+	config := ccserver.LoadConfig()
 
 	chaincode, err := contractapi.NewChaincode(new(SmartContract))
 
@@ -157,16 +148,7 @@ func main() {
 		return
 	}
 
-	server := &shim.ChaincodeServer{
-		CCID:    config.CCID,
-		Address: config.Address,
-		CC:      chaincode,
-		TLSProps: shim.TLSProperties{
-			Disabled: true,
-		},
-	}
-
-	if err := server.Start(); err != nil {
+	if err := ccserver.Run(config, chaincode); err != nil {
 		fmt.Printf("Error starting fabcar chaincode: %s", err.Error())
 	}
 }
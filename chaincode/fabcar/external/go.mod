@@ -3,6 +3,8 @@ module github.com/hyperledger/fabric-samples/chaincode/fabcar/external
 go 1.13
 
 require (
-	github.com/hyperledger/fabric-chaincode-go v0.0.0-20200424173110-d7076418f212
 	github.com/hyperledger/fabric-contract-api-go v1.1.0
+	github.com/hyperledger/fabric-samples/chaincode/ccserver v0.0.0
 )
+
+replace github.com/hyperledger/fabric-samples/chaincode/ccserver => ../../ccserver
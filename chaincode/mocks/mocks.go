@@ -0,0 +1,36 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package mocks provides the test doubles shared by the unit tests of the Go smart contracts:
+// the counterfeiter fakes of the transaction context, chaincode stub and client identity,
+// and WorldState, an in-memory chaincode stub that behaves like the world state of a peer.
+package mocks
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+//go:generate counterfeiter -o transaction.go -fake-name TransactionContext github.com/hyperledger/fabric-contract-api-go/contractapi.TransactionContextInterface
+//go:generate counterfeiter -o chaincodestub.go -fake-name ChaincodeStub github.com/hyperledger/fabric-chaincode-go/shim.ChaincodeStubInterface
+//go:generate counterfeiter -o clientIdentity.go -fake-name ClientIdentity github.com/hyperledger/fabric-chaincode-go/pkg/cid.ClientIdentity
+
+var (
+	_ contractapi.TransactionContextInterface = &TransactionContext{}
+	_ shim.ChaincodeStubInterface             = &WorldState{}
+	_ cid.ClientIdentity                      = &ClientIdentity{}
+)
+
+// NewTransactionContext returns a transaction context submitted by the client over the world state
+func NewTransactionContext(worldState *WorldState, clientID string, mspID string) *TransactionContext {
+	clientIdentity := &ClientIdentity{}
+	clientIdentity.GetIDReturns(clientID, nil)
+	clientIdentity.GetMSPIDReturns(mspID, nil)
+	transactionContext := &TransactionContext{}
+	transactionContext.GetStubReturns(worldState)
+	transactionContext.GetClientIdentityReturns(clientIdentity)
+
+	return transactionContext
+}
@@ -0,0 +1,344 @@
+package mocks
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// emptyKeySubstitute replaces an empty start key of a range query, so that the range
+// excludes composite keys like it does on a peer
+const emptyKeySubstitute = "\x01"
+
+// WorldState is an in-memory fake of shim.ChaincodeStubInterface. It keeps the world state
+// in a map and supports state reads and writes, composite keys, range and partial composite
+// key queries with or without pagination, transaction IDs and timestamps, and events.
+// Like on a peer, the writes and the event of a transaction are not visible to its own reads:
+// they are pending until the transaction is committed with Commit, or discarded with Rollback.
+// Like on a peer as well, a transaction can not write after a paginated query, nor run a paginated
+// query after a write.
+// The other functions of the stub are served by the embedded ChaincodeStub fake.
+type WorldState struct {
+	ChaincodeStub
+
+	state        map[string][]byte
+	writes       map[string][]byte
+	txID         string
+	txTimestamp  *timestamp.Timestamp
+	eventName    string
+	eventPayload []byte
+	event        *peer.ChaincodeEvent
+	// paginatedQuery is set once the transaction ran a paginated query
+	paginatedQuery bool
+}
+
+// NewWorldState returns an empty world state, on which a transaction with the given ID
+// and timestamp is running
+func NewWorldState(txID string, txTime time.Time) *WorldState {
+	worldState := &WorldState{state: map[string][]byte{}, writes: map[string][]byte{}}
+	worldState.StartTransaction(txID, txTime)
+
+	return worldState
+}
+
+// StartTransaction runs a new transaction with the given ID and timestamp on the world state,
+// discarding the pending writes and event of the previous transaction
+func (w *WorldState) StartTransaction(txID string, txTime time.Time) {
+	txTimestamp, err := ptypes.TimestampProto(txTime)
+	if err != nil {
+		panic(fmt.Sprintf("invalid transaction timestamp: %v", err))
+	}
+
+	w.txID = txID
+	w.txTimestamp = txTimestamp
+	w.Rollback()
+	w.event = nil
+}
+
+// Commit applies the pending writes of the transaction to the world state, and publishes
+// its event. The next transaction keeps the same ID and timestamp.
+func (w *WorldState) Commit() {
+	for key, value := range w.writes {
+		if value == nil {
+			delete(w.state, key)
+		} else {
+			w.state[key] = value
+		}
+	}
+	w.event = nil
+	if w.eventName != "" {
+		w.event = &peer.ChaincodeEvent{TxId: w.txID, EventName: w.eventName, Payload: w.eventPayload}
+	}
+	w.Rollback()
+}
+
+// Rollback discards the pending writes and event of the transaction, like a peer does
+// with a transaction that failed
+func (w *WorldState) Rollback() {
+	w.writes = map[string][]byte{}
+	w.eventName = ""
+	w.eventPayload = nil
+	w.paginatedQuery = false
+}
+
+// Submit ends the transaction that returned err the way a peer would: its writes and event are
+// committed to the world state when it succeeded, and discarded when it failed
+func (w *WorldState) Submit(err error) error {
+	if err != nil {
+		w.Rollback()
+		return err
+	}
+	w.Commit()
+
+	return nil
+}
+
+// Event returns the name and payload of the event of the last committed transaction
+func (w *WorldState) Event() (string, []byte) {
+	if w.event == nil {
+		return "", nil
+	}
+
+	return w.event.EventName, w.event.Payload
+}
+
+// Keys returns the committed keys of the world state in sorted order
+func (w *WorldState) Keys() []string {
+	keys := make([]string, 0, len(w.state))
+	for key := range w.state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// GetTxID returns the ID of the current transaction
+func (w *WorldState) GetTxID() string {
+	return w.txID
+}
+
+// GetTxTimestamp returns the timestamp of the current transaction
+func (w *WorldState) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return w.txTimestamp, nil
+}
+
+// GetState returns the committed value of the key, or nil if the key does not exist
+func (w *WorldState) GetState(key string) ([]byte, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be an empty string")
+	}
+
+	return w.state[key], nil
+}
+
+// PutState sets the value of the key when the transaction is committed
+func (w *WorldState) PutState(key string, value []byte) error {
+	err := w.checkWrite(key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	w.writes[key] = value
+
+	return nil
+}
+
+// DelState deletes the key when the transaction is committed
+func (w *WorldState) DelState(key string) error {
+	err := w.checkWrite(key)
+	if err != nil {
+		return err
+	}
+	w.writes[key] = nil
+
+	return nil
+}
+
+// checkWrite returns an error when the key can not be written by the transaction
+func (w *WorldState) checkWrite(key string) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	if w.paginatedQuery {
+		return fmt.Errorf("txid [%s]: Transaction has already performed a paginated query. Writes are not allowed", w.txID)
+	}
+
+	return nil
+}
+
+// SetEvent sets the event of the current transaction, replacing any event set before.
+// The event is published when the transaction is committed.
+func (w *WorldState) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return fmt.Errorf("event name can not be empty string")
+	}
+	w.eventName = name
+	w.eventPayload = payload
+
+	return nil
+}
+
+// CreateCompositeKey combines the object type and attributes into a composite key
+func (w *WorldState) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return shim.CreateCompositeKey(objectType, attributes)
+}
+
+// SplitCompositeKey splits a composite key into its object type and attributes
+func (w *WorldState) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	if len(compositeKey) < 2 || compositeKey[0] != 0x00 {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+
+	components := []string{}
+	componentIndex := 1
+	for i := 1; i < len(compositeKey); i++ {
+		if compositeKey[i] == 0x00 {
+			components = append(components, compositeKey[componentIndex:i])
+			componentIndex = i + 1
+		}
+	}
+
+	return components[0], components[1:], nil
+}
+
+// GetStateByRange returns an iterator over the simple keys from startKey, included, to endKey, excluded.
+// An empty startKey or endKey leaves the range open on that side.
+func (w *WorldState) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	iterator, _, err := w.queryKeyRange(startKey, endKey, 0, "")
+
+	return iterator, err
+}
+
+// GetStateByRangeWithPagination returns an iterator over a page of at most pageSize simple keys
+// from startKey, or from the bookmark when set, to endKey. A pageSize of 0 returns every key.
+func (w *WorldState) GetStateByRangeWithPagination(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	err := w.startPaginatedQuery()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return w.queryKeyRange(startKey, endKey, pageSize, bookmark)
+}
+
+// queryKeyRange returns the simple keys of the range, at most pageSize of them unless pageSize is 0
+func (w *WorldState) queryKeyRange(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	for _, key := range []string{startKey, endKey} {
+		if len(key) > 0 && key[0] == 0x00 {
+			return nil, nil, fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+		}
+	}
+
+	return w.queryRange(startKey, endKey, pageSize, bookmark)
+}
+
+// GetStateByPartialCompositeKey returns an iterator over the composite keys of the object type
+// whose attributes start with the given attributes
+func (w *WorldState) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	iterator, _, err := w.queryPartialCompositeKey(objectType, attributes, 0, "")
+
+	return iterator, err
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns an iterator over a page of at most pageSize
+// composite keys matching the partial composite key, starting from the bookmark when set
+func (w *WorldState) GetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	err := w.startPaginatedQuery()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return w.queryPartialCompositeKey(objectType, attributes, pageSize, bookmark)
+}
+
+// queryPartialCompositeKey returns the composite keys matching the partial composite key,
+// at most pageSize of them unless pageSize is 0
+func (w *WorldState) queryPartialCompositeKey(objectType string, attributes []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	startKey, err := shim.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+	endKey := startKey + string(utf8.MaxRune)
+
+	return w.queryRange(startKey, endKey, pageSize, bookmark)
+}
+
+// startPaginatedQuery records that the transaction runs a paginated query, which is only allowed
+// in a transaction that did not write
+func (w *WorldState) startPaginatedQuery() error {
+	if len(w.writes) > 0 {
+		return fmt.Errorf("txid [%s]: Paginated queries are supported only in a read-only transaction", w.txID)
+	}
+	w.paginatedQuery = true
+
+	return nil
+}
+
+// queryRange returns the keys from startKey, or from the bookmark when it is within the range,
+// to endKey, at most pageSize of them unless pageSize is 0. The returned bookmark is the key
+// at which the next page starts, or empty once the range is exhausted.
+func (w *WorldState) queryRange(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if pageSize < 0 {
+		return nil, nil, fmt.Errorf("page size must not be negative")
+	}
+	if bookmark > startKey {
+		startKey = bookmark
+	}
+
+	iterator := &stateIterator{}
+	metadata := &peer.QueryResponseMetadata{}
+	for _, key := range w.Keys() {
+		if key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		if pageSize > 0 && int32(len(iterator.results)) == pageSize {
+			metadata.Bookmark = key
+			break
+		}
+		iterator.results = append(iterator.results, &queryresult.KV{Key: key, Value: w.state[key]})
+	}
+	metadata.FetchedRecordsCount = int32(len(iterator.results))
+
+	return iterator, metadata, nil
+}
+
+// stateIterator iterates over a snapshot of the results of a query
+type stateIterator struct {
+	results []*queryresult.KV
+	closed  bool
+}
+
+// HasNext returns true while the iterator has results left
+func (i *stateIterator) HasNext() bool {
+	return !i.closed && len(i.results) > 0
+}
+
+// Next returns the next result
+func (i *stateIterator) Next() (*queryresult.KV, error) {
+	if !i.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	result := i.results[0]
+	i.results = i.results[1:]
+
+	return result, nil
+}
+
+// Close closes the iterator
+func (i *stateIterator) Close() error {
+	i.closed = true
+
+	return nil
+}
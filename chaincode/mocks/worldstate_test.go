@@ -0,0 +1,58 @@
+package mocks_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorldStateTransaction(t *testing.T) {
+	worldState := mocks.NewWorldState("tx1", time.Unix(1600000000, 0))
+
+	require.NoError(t, worldState.PutState("a", []byte("1")))
+	require.NoError(t, worldState.SetEvent("Written", []byte("a")))
+	value, err := worldState.GetState("a")
+	require.NoError(t, err)
+	require.Nil(t, value, "writes are not visible to the transaction")
+
+	require.NoError(t, worldState.Submit(nil))
+	require.Equal(t, []string{"a"}, worldState.Keys())
+	name, payload := worldState.Event()
+	require.Equal(t, "Written", name)
+	require.Equal(t, "a", string(payload))
+
+	require.NoError(t, worldState.DelState("a"))
+	require.NoError(t, worldState.PutState("b", []byte("2")))
+	require.EqualError(t, worldState.Submit(errors.New("failed")), "failed")
+	require.Equal(t, []string{"a"}, worldState.Keys())
+}
+
+func TestWorldStatePaginatedQuery(t *testing.T) {
+	worldState := mocks.NewWorldState("tx1", time.Unix(1600000000, 0))
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, worldState.PutState(key, []byte(key)))
+	}
+	worldState.Commit()
+
+	_, _, err := worldState.GetStateByRangeWithPagination("", "", 2, "")
+	require.NoError(t, err)
+	require.EqualError(t, worldState.PutState("d", []byte("d")), "txid [tx1]: Transaction has already performed a paginated query. Writes are not allowed")
+	require.EqualError(t, worldState.DelState("a"), "txid [tx1]: Transaction has already performed a paginated query. Writes are not allowed")
+	worldState.Rollback()
+
+	// a query that is not paginated does not prevent writes
+	_, err = worldState.GetStateByRange("", "")
+	require.NoError(t, err)
+	require.NoError(t, worldState.PutState("d", []byte("d")))
+	_, _, err = worldState.GetStateByPartialCompositeKeyWithPagination("type", []string{}, 2, "")
+	require.EqualError(t, err, "txid [tx1]: Paginated queries are supported only in a read-only transaction")
+	worldState.Commit()
+
+	iterator, metadata, err := worldState.GetStateByRangeWithPagination("", "", 2, "")
+	require.NoError(t, err)
+	require.Equal(t, "c", metadata.Bookmark)
+	require.True(t, iterator.HasNext())
+}
@@ -164,8 +164,15 @@ func (tc *TokenContract) TransferTokens(ctx contractapi.TransactionContextInterf
 	return nil
 }
 
+// newChaincode returns the chaincode serving both contracts. TokenERC1155Contract is registered first,
+// so that it stays the default contract and its functions are called without a contract name prefix.
+// The functions of TokenContract are called with the "TokenContract:" prefix.
+func newChaincode() (*contractapi.ContractChaincode, error) {
+	return contractapi.NewChaincode(&TokenERC1155Contract{}, &TokenContract{})
+}
+
 func main() {
-	chaincode, err := contractapi.NewChaincode(&TokenContract{})
+	chaincode, err := newChaincode()
 	if err != nil {
 		fmt.Printf("Error creating token chaincode: %v\n", err)
 		return
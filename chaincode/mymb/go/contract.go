@@ -4,7 +4,6 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"github.com/google/uuid"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"strconv"
 	"time"
@@ -45,27 +44,34 @@ const (
 	balancePrefix = "balance"
 )
 
+// MintToken 은 토큰을 생성하여 저장한다.
+// tokenID 가 비어 있으면 트랜잭션 ID 와 카운터로 TokenID 를 생성하고, 주어지면 그대로 사용한다.
+// 모든 endorsing peer 가 같은 write set 을 만들도록 TokenID 와 생성 시간은 트랜잭션에서만 가져온다.
 func (c *TokenERC1155Contract) MintToken(ctx contractapi.TransactionContextInterface, tokenID string,
 	categoryCode uint64, pollingResultID uint64, tokenType string, sellStage string) (*Token1155, error) {
 
-	// UUID 생성
-	uuid := uuid.New()
-
-	// TokenID 생성
-	tokenID = fmt.Sprintf("0x%x", sha256.Sum256([]byte(uuid.String())))
-
-	/*
-		// 유니크한 데이터 생성
-		uniqueData := fmt.Sprintf("%d%d%d%d%s", categoryCode, pollingResultID, totalTicket, amount, time.Now().String())
-
-		// SHA256 해시 생성
-		hash := sha256.New()
-		hash.Write([]byte(uniqueData))
-		hashBytes := hash.Sum(nil)
+	// TokenID 생성 또는 중복 확인
+	var err error
+	if tokenID == "" {
+		tokenID, err = newTokenID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		exists, err := tokenExists(ctx, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("token with ID %s already exists", tokenID)
+		}
+	}
 
-		// TokenID 생성 (0x를 앞에 붙여서 생성)
-		tokenID = fmt.Sprintf("0x%x", hashBytes)
-	*/
+	// 트랜잭션 생성 시간
+	createdTime, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Token 생성
 	token := Token1155{
@@ -74,7 +80,7 @@ func (c *TokenERC1155Contract) MintToken(ctx contractapi.TransactionContextInter
 		PollingResultID:  pollingResultID,
 		TokenType:        tokenType,
 		SellStage:        sellStage,
-		TokenCreatedTime: time.Now(), // 현재 시간 사용
+		TokenCreatedTime: createdTime, // 트랜잭션 시간 사용
 	}
 
 	// TokenID, Token 저장
@@ -99,12 +105,18 @@ func (c *TokenERC1155Contract) MintToken(ctx contractapi.TransactionContextInter
 func (c *TokenERC1155Contract) CreateUserBlock(ctx contractapi.TransactionContextInterface,
 	nickname string, mymPoint uint64, ownedToken []string) error {
 
+	// 트랜잭션 생성 시간
+	createdTime, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
 	// User 생성
 	user := User{
 		NickName:         nickname,
 		MymPoint:         mymPoint,
 		OwnedToken:       ownedToken,
-		BlockCreatedTime: time.Now(),
+		BlockCreatedTime: createdTime,
 	}
 
 	// User 블록 저장
@@ -341,14 +353,45 @@ func (c *TokenERC1155Contract) TransferToken(ctx contractapi.TransactionContextI
 	return nil
 }
 
-func main() {
-	// The main function is not required for Hyperledger Fabric chaincode
-	// It's here only for demonstration purposes
-	cc, err := contractapi.NewChaincode(new(TokenERC1155Contract))
+// newTokenID 는 트랜잭션 ID 와 카운터의 SHA256 해시로 TokenID 를 생성한다.
+// 카운터는 0 부터 시작하며, 생성된 TokenID 가 이미 존재하면 증가한다.
+func newTokenID(ctx contractapi.TransactionContextInterface) (string, error) {
+	txID := ctx.GetStub().GetTxID()
+
+	for counter := uint64(0); ; counter++ {
+		tokenID := fmt.Sprintf("0x%x", sha256.Sum256([]byte(txID+":"+strconv.FormatUint(counter, 10))))
+
+		exists, err := tokenExists(ctx, tokenID)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return tokenID, nil
+		}
+	}
+}
+
+// tokenExists 는 TokenID 의 토큰이 저장되어 있는지 확인한다.
+func tokenExists(ctx contractapi.TransactionContextInterface, tokenID string) (bool, error) {
+	tokenKey, err := ctx.GetStub().CreateCompositeKey(tokenPrefix, []string{tokenID})
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	tokenBytes, err := ctx.GetStub().GetState(tokenKey)
 	if err != nil {
-		panic(err.Error())
+		return false, fmt.Errorf("failed to get state: %v", err)
 	}
-	if err := cc.Start(); err != nil {
-		fmt.Printf("Error starting TokenERC1155Contract chaincode: %s", err)
+
+	return tokenBytes != nil, nil
+}
+
+// txTime 은 트랜잭션 생성 시간을 반환한다. time.Now() 와 달리 모든 endorsing peer 에서 같다.
+func txTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
 	}
+
+	return time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).UTC(), nil
 }
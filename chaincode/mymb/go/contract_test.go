@@ -0,0 +1,181 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// endorserStub 는 endorsing peer 의 시뮬레이션처럼 읽은 키와 쓴 키를 기록한다.
+type endorserStub struct {
+	*shimtest.MockStub
+	reads  map[string]string
+	writes map[string]string
+}
+
+func (s *endorserStub) GetState(key string) ([]byte, error) {
+	value, err := s.MockStub.GetState(key)
+	s.reads[key] = string(value)
+	return value, err
+}
+
+func (s *endorserStub) PutState(key string, value []byte) error {
+	s.writes[key] = string(value)
+	return s.MockStub.PutState(key, value)
+}
+
+// endorsement 는 하나의 endorsing peer 가 만든 read/write set 과 응답이다.
+type endorsement struct {
+	Reads    map[string]string
+	Writes   map[string]string
+	Response string
+}
+
+// endorse 는 같은 world state 를 가진 peer 에서 트랜잭션 제안을 시뮬레이션한다.
+func endorse(t *testing.T, state map[string]string, txID string, txTimestamp *timestamp.Timestamp,
+	invoke func(ctx contractapi.TransactionContextInterface) (interface{}, error)) endorsement {
+
+	stub := &endorserStub{MockStub: shimtest.NewMockStub("mymb", nil)}
+	stub.MockTransactionStart("genesis")
+	for key, value := range state {
+		if err := stub.MockStub.PutState(key, []byte(value)); err != nil {
+			t.Fatalf("failed to seed world state: %v", err)
+		}
+	}
+	stub.MockTransactionEnd("genesis")
+
+	stub.reads = map[string]string{}
+	stub.writes = map[string]string{}
+	stub.MockTransactionStart(txID)
+	stub.TxTimestamp = txTimestamp
+	defer stub.MockTransactionEnd(txID)
+
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+
+	response, err := invoke(ctx)
+	if err != nil {
+		t.Fatalf("endorsement failed: %v", err)
+	}
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+
+	return endorsement{stub.reads, stub.writes, string(responseBytes)}
+}
+
+// endorseTwice 는 두 peer 에서 같은 트랜잭션 제안을 시뮬레이션하고,
+// 두 endorsement 가 같은지 확인한다.
+func endorseTwice(t *testing.T, state map[string]string,
+	invoke func(ctx contractapi.TransactionContextInterface) (interface{}, error)) endorsement {
+
+	txID := "f2a7c1e0"
+	txTimestamp := &timestamp.Timestamp{Seconds: 1700000000, Nanos: 500}
+
+	org1 := endorse(t, state, txID, txTimestamp, invoke)
+	org2 := endorse(t, state, txID, txTimestamp, invoke)
+
+	if !reflect.DeepEqual(org1, org2) {
+		t.Fatalf("endorsements differ:\norg1: %+v\norg2: %+v", org1, org2)
+	}
+	if len(org1.Writes) == 0 {
+		t.Fatalf("endorsement has no writes")
+	}
+	return org1
+}
+
+func TestMintTokenDerivesTokenIDFromTransaction(t *testing.T) {
+	contract := new(TokenERC1155Contract)
+
+	result := endorseTwice(t, nil, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return contract.MintToken(ctx, "", 1, 2, "VIP", "presale")
+	})
+
+	var token Token1155
+	if err := json.Unmarshal([]byte(result.Response), &token); err != nil {
+		t.Fatalf("failed to unmarshal token: %v", err)
+	}
+	if expected := fmt.Sprintf("0x%x", sha256.Sum256([]byte("f2a7c1e0:0"))); token.TokenID != expected {
+		t.Fatalf("unexpected token ID %s", token.TokenID)
+	}
+	if !token.TokenCreatedTime.Equal(time.Unix(1700000000, 500)) {
+		t.Fatalf("token created time %v is not the transaction timestamp", token.TokenCreatedTime)
+	}
+}
+
+func TestMintTokenSkipsTakenTokenIDs(t *testing.T) {
+	contract := new(TokenERC1155Contract)
+	mint := func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return contract.MintToken(ctx, "", 1, 2, "VIP", "presale")
+	}
+
+	first := endorseTwice(t, nil, mint)
+	second := endorseTwice(t, first.Writes, mint)
+
+	// 두 트랜잭션의 ID 가 같으므로 두 번째 mint 는 카운터 1 의 TokenID 를 사용한다
+	for key := range first.Writes {
+		if _, ok := second.Writes[key]; ok {
+			t.Fatalf("second mint overwrote %q", key)
+		}
+	}
+}
+
+func TestMintTokenWithClientSuppliedID(t *testing.T) {
+	contract := new(TokenERC1155Contract)
+	mint := func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return contract.MintToken(ctx, "ticket-1", 1, 2, "VIP", "presale")
+	}
+
+	result := endorseTwice(t, nil, mint)
+
+	stub := shimtest.NewMockStub("mymb", nil)
+	stub.MockTransactionStart("genesis")
+	for key, value := range result.Writes {
+		if err := stub.PutState(key, []byte(value)); err != nil {
+			t.Fatalf("failed to seed world state: %v", err)
+		}
+	}
+	stub.MockTransactionEnd("genesis")
+	stub.MockTransactionStart("f2a7c1e1")
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+
+	_, err := contract.MintToken(ctx, "ticket-1", 1, 2, "VIP", "presale")
+	if err == nil || err.Error() != "token with ID ticket-1 already exists" {
+		t.Fatalf("expected collision error, got %v", err)
+	}
+}
+
+func TestCreateUserBlockUsesTransactionTimestamp(t *testing.T) {
+	contract := new(TokenERC1155Contract)
+
+	result := endorseTwice(t, nil, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return nil, contract.CreateUserBlock(ctx, "alice", 10, []string{"ticket-1"})
+	})
+
+	var user User
+	if err := json.Unmarshal([]byte(result.Writes["alice"]), &user); err != nil {
+		t.Fatalf("failed to unmarshal user block: %v", err)
+	}
+	if !user.BlockCreatedTime.Equal(time.Unix(1700000000, 500)) {
+		t.Fatalf("block created time %v is not the transaction timestamp", user.BlockCreatedTime)
+	}
+}
+
+func TestChaincodeDefaultContract(t *testing.T) {
+	chaincode, err := newChaincode()
+	if err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+	if chaincode.DefaultContract != "TokenERC1155Contract" {
+		t.Fatalf("default contract is %s, want TokenERC1155Contract", chaincode.DefaultContract)
+	}
+}
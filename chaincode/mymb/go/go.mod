@@ -3,6 +3,7 @@ module github.com/dobinDev/fabric-samples/chaincode/mymb/go
 go 1.14
 
 require (
-	github.com/google/uuid v1.6.0
+	github.com/golang/protobuf v1.3.2
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20200424173110-d7076418f212
 	github.com/hyperledger/fabric-contract-api-go v1.1.0
 )
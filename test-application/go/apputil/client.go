@@ -0,0 +1,218 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package apputil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// Client connects to the network of the configuration as any of its identities.
+// The gateway connection of each identity is opened on first use and reused until Close.
+type Client struct {
+	config    *Config
+	wallet    *gateway.Wallet
+	mutex     sync.Mutex
+	gateways  map[string]*gateway.Gateway
+	contracts map[string]*Contract
+}
+
+// NewClient opens the wallet of the configuration
+func NewClient(config *Config) (*Client, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DiscoveryAsLocalhost {
+		err = os.Setenv("DISCOVERY_AS_LOCALHOST", "true")
+		if err != nil {
+			return nil, fmt.Errorf("error setting DISCOVERY_AS_LOCALHOST environment variable: %v", err)
+		}
+	}
+
+	wallet, err := gateway.NewFileSystemWallet(config.WalletPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wallet: %v", err)
+	}
+
+	return &Client{
+		config:    config,
+		wallet:    wallet,
+		gateways:  make(map[string]*gateway.Gateway),
+		contracts: make(map[string]*Contract),
+	}, nil
+}
+
+// Contract returns the contract of the configuration, used as the default identity
+func (c *Client) Contract() (*Contract, error) {
+	return c.ContractAs(c.config.Identity)
+}
+
+// ContractAs returns the contract of the configuration, used as the identity with the given label
+func (c *Client) ContractAs(identity string) (*Contract, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if contract, ok := c.contracts[identity]; ok {
+		return contract, nil
+	}
+
+	gw, err := c.connect(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := gw.GetNetwork(c.config.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network: %v", err)
+	}
+
+	contract := &Contract{Contract: network.GetContract(c.config.Chaincode)}
+	c.contracts[identity] = contract
+
+	return contract, nil
+}
+
+// Close closes the gateway connections of every identity
+func (c *Client) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, gw := range c.gateways {
+		gw.Close()
+	}
+	c.gateways = make(map[string]*gateway.Gateway)
+	c.contracts = make(map[string]*Contract)
+}
+
+// connect returns the gateway connection of the identity, importing the identity into the wallet if needed
+func (c *Client) connect(identity string) (*gateway.Gateway, error) {
+	if gw, ok := c.gateways[identity]; ok {
+		return gw, nil
+	}
+
+	if !c.wallet.Exists(identity) {
+		err := c.populateWallet(identity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to populate wallet contents: %v", err)
+		}
+	}
+
+	gw, err := gateway.Connect(
+		gateway.WithConfig(config.FromFile(filepath.Clean(c.config.ProfilePath))),
+		gateway.WithIdentity(c.wallet, identity),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gateway: %v", err)
+	}
+	c.gateways[identity] = gw
+
+	return gw, nil
+}
+
+// populateWallet imports the identity from its MSP directory into the wallet
+func (c *Client) populateWallet(label string) error {
+	identityConfig, ok := c.config.Identities[label]
+	if !ok || identityConfig.CredPath == "" {
+		return fmt.Errorf("identity %s is not in the wallet and has no credential path", label)
+	}
+	if identityConfig.MSPID == "" {
+		return fmt.Errorf("identity %s has no MSP ID", label)
+	}
+	log.Printf("============ Populating wallet with %s ============", label)
+
+	certPath := filepath.Join(identityConfig.CredPath, "signcerts", "cert.pem")
+	// read the certificate pem
+	cert, err := ioutil.ReadFile(filepath.Clean(certPath))
+	if err != nil {
+		return err
+	}
+
+	keyDir := filepath.Join(identityConfig.CredPath, "keystore")
+	// there's a single file in this dir containing the private key
+	files, err := ioutil.ReadDir(keyDir)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("keystore folder should have contain one file")
+	}
+	keyPath := filepath.Join(keyDir, files[0].Name())
+	key, err := ioutil.ReadFile(filepath.Clean(keyPath))
+	if err != nil {
+		return err
+	}
+
+	identity := gateway.NewX509Identity(identityConfig.MSPID, string(cert), string(key))
+
+	return c.wallet.Put(label, identity)
+}
+
+// Contract wraps a gateway contract with transaction functions reporting the
+// name of the failed transaction and decoding JSON results
+type Contract struct {
+	*gateway.Contract
+}
+
+// Submit submits the transaction to be endorsed and committed to the ledger
+func (c *Contract) Submit(name string, args ...string) ([]byte, error) {
+	result, err := c.SubmitTransaction(name, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction %s: %v", name, err)
+	}
+
+	return result, nil
+}
+
+// Evaluate evaluates the transaction on a peer without submitting it to the ledger
+func (c *Contract) Evaluate(name string, args ...string) ([]byte, error) {
+	result, err := c.EvaluateTransaction(name, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction %s: %v", name, err)
+	}
+
+	return result, nil
+}
+
+// SubmitJSON submits the transaction and decodes its JSON result into result
+func (c *Contract) SubmitJSON(result interface{}, name string, args ...string) error {
+	data, err := c.Submit(name, args...)
+	if err != nil {
+		return err
+	}
+
+	return decodeResult(data, result, name)
+}
+
+// EvaluateJSON evaluates the transaction and decodes its JSON result into result
+func (c *Contract) EvaluateJSON(result interface{}, name string, args ...string) error {
+	data, err := c.Evaluate(name, args...)
+	if err != nil {
+		return err
+	}
+
+	return decodeResult(data, result, name)
+}
+
+// decodeResult decodes the JSON result of a transaction
+func decodeResult(data []byte, result interface{}, name string) error {
+	err := json.Unmarshal(data, result)
+	if err != nil {
+		return fmt.Errorf("failed to decode result of transaction %s: %v", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package apputil
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Environment variables read by Flags.Config. They override the configuration file
+// and are overridden by the command line flags.
+const (
+	EnvConfigFile = "FABRIC_APP_CONFIG"
+	EnvProfile    = "FABRIC_APP_PROFILE"
+	EnvWallet     = "FABRIC_APP_WALLET"
+	EnvMSPID      = "FABRIC_APP_MSPID"
+	EnvIdentity   = "FABRIC_APP_IDENTITY"
+	EnvCredPath   = "FABRIC_APP_CRED_PATH"
+	EnvChannel    = "FABRIC_APP_CHANNEL"
+	EnvChaincode  = "FABRIC_APP_CHAINCODE"
+)
+
+// testNetworkPath is the path of the test network from the directory of a sample application
+var testNetworkPath = filepath.Join("..", "..", "test-network")
+
+// IdentityConfig describes an identity of the wallet
+type IdentityConfig struct {
+	// MSPID is the MSP the identity belongs to
+	MSPID string `yaml:"mspID"`
+	// CredPath is the MSP directory the identity is imported from when it is missing from the
+	// wallet. It must hold the certificate in signcerts/cert.pem and a single key in keystore.
+	CredPath string `yaml:"credPath"`
+}
+
+// Config describes how an application connects to a network and which contract it uses
+type Config struct {
+	// ProfilePath is the path of the connection profile
+	ProfilePath string `yaml:"profilePath"`
+	// WalletPath is the directory of the file system wallet
+	WalletPath string `yaml:"walletPath"`
+	// Identity is the label of the identity used when no other is requested
+	Identity string `yaml:"identity"`
+	// Identities are the identities the application can connect as, by label
+	Identities map[string]IdentityConfig `yaml:"identities"`
+	// Channel is the channel the contract is deployed on
+	Channel string `yaml:"channel"`
+	// Chaincode is the name of the chaincode
+	Chaincode string `yaml:"chaincode"`
+	// DiscoveryAsLocalhost maps the addresses of discovered peers to localhost,
+	// as required when the application runs outside of the test network containers
+	DiscoveryAsLocalhost bool `yaml:"discoveryAsLocalhost"`
+}
+
+// DefaultConfig returns the configuration connecting as User1 of Org1 to the chaincode
+// on mychannel of the test network, from the directory of a sample application
+func DefaultConfig(chaincode string) Config {
+	org1Path := filepath.Join(testNetworkPath, "organizations", "peerOrganizations", "org1.example.com")
+
+	return Config{
+		ProfilePath: filepath.Join(org1Path, "connection-org1.yaml"),
+		WalletPath:  "wallet",
+		Identity:    "appUser",
+		Identities: map[string]IdentityConfig{
+			"appUser": {
+				MSPID:    "Org1MSP",
+				CredPath: filepath.Join(org1Path, "users", "User1@org1.example.com", "msp"),
+			},
+		},
+		Channel:              "mychannel",
+		Chaincode:            chaincode,
+		DiscoveryAsLocalhost: true,
+	}
+}
+
+// LoadConfigFile overrides the configuration with the settings of the YAML file.
+// Settings missing from the file are left unchanged, while an identity listed in the
+// file replaces the identity of the configuration with the same label.
+func (c *Config) LoadConfigFile(path string) error {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %v", err)
+	}
+
+	err = yaml.UnmarshalStrict(data, c)
+	if err != nil {
+		return fmt.Errorf("failed to parse configuration file %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// Validate checks that the configuration is complete
+func (c *Config) Validate() error {
+	switch {
+	case c.ProfilePath == "":
+		return fmt.Errorf("connection profile path must be set")
+	case c.WalletPath == "":
+		return fmt.Errorf("wallet path must be set")
+	case c.Identity == "":
+		return fmt.Errorf("identity must be set")
+	case c.Channel == "":
+		return fmt.Errorf("channel must be set")
+	case c.Chaincode == "":
+		return fmt.Errorf("chaincode must be set")
+	}
+
+	return nil
+}
+
+// setIdentity overrides the non-empty settings of the identity with the given label
+func (c *Config) setIdentity(label string, mspID string, credPath string) {
+	if mspID == "" && credPath == "" {
+		return
+	}
+	if c.Identities == nil {
+		c.Identities = make(map[string]IdentityConfig)
+	}
+	identity := c.Identities[label]
+	setString(&identity.MSPID, mspID)
+	setString(&identity.CredPath, credPath)
+	c.Identities[label] = identity
+}
+
+// Flags are the command line flags overriding the configuration
+type Flags struct {
+	flagSet    *flag.FlagSet
+	configFile string
+	profile    string
+	wallet     string
+	mspID      string
+	identity   string
+	credPath   string
+	channel    string
+	chaincode  string
+}
+
+// RegisterFlags defines the configuration flags on the flag set, which the
+// application parses before calling Config
+func RegisterFlags(flagSet *flag.FlagSet) *Flags {
+	f := &Flags{flagSet: flagSet}
+	flagSet.StringVar(&f.configFile, "config", "", "YAML configuration file, also set with "+EnvConfigFile)
+	flagSet.StringVar(&f.profile, "profile", "", "connection profile path, also set with "+EnvProfile)
+	flagSet.StringVar(&f.wallet, "wallet", "", "wallet directory, also set with "+EnvWallet)
+	flagSet.StringVar(&f.mspID, "msp", "", "MSP ID of the identity, also set with "+EnvMSPID)
+	flagSet.StringVar(&f.identity, "identity", "", "wallet label of the identity, also set with "+EnvIdentity)
+	flagSet.StringVar(&f.credPath, "cred-path", "", "MSP directory the identity is imported from, also set with "+EnvCredPath)
+	flagSet.StringVar(&f.channel, "channel", "", "channel name, also set with "+EnvChannel)
+	flagSet.StringVar(&f.chaincode, "chaincode", "", "chaincode name, also set with "+EnvChaincode)
+
+	return f
+}
+
+// Config returns the defaults overridden, in order, by the configuration file, the
+// environment and the command line flags. The MSP ID and credential path settings
+// apply to the selected identity.
+func (f *Flags) Config(defaults Config) (*Config, error) {
+	config := defaults
+	config.Identities = make(map[string]IdentityConfig, len(defaults.Identities))
+	for label, identity := range defaults.Identities {
+		config.Identities[label] = identity
+	}
+
+	configFile := f.value("config", f.configFile, EnvConfigFile)
+	if configFile != "" {
+		err := config.LoadConfigFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	setString(&config.ProfilePath, f.value("profile", f.profile, EnvProfile))
+	setString(&config.WalletPath, f.value("wallet", f.wallet, EnvWallet))
+	setString(&config.Identity, f.value("identity", f.identity, EnvIdentity))
+	setString(&config.Channel, f.value("channel", f.channel, EnvChannel))
+	setString(&config.Chaincode, f.value("chaincode", f.chaincode, EnvChaincode))
+	config.setIdentity(config.Identity, f.value("msp", f.mspID, EnvMSPID), f.value("cred-path", f.credPath, EnvCredPath))
+
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// value returns the flag value when the flag is set, and the environment variable otherwise
+func (f *Flags) value(name string, flagValue string, env string) string {
+	set := false
+	f.flagSet.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			set = true
+		}
+	})
+	if set {
+		return flagValue
+	}
+
+	return os.Getenv(env)
+}
+
+// setString overrides the setting unless the value is empty
+func setString(setting *string, value string) {
+	if value != "" {
+		*setting = value
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package apputil_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/test-application/go/apputil"
+	"github.com/stretchr/testify/require"
+)
+
+const configYAML = `
+channel: filechannel
+chaincode: filechaincode
+discoveryAsLocalhost: false
+identities:
+  admin:
+    mspID: Org2MSP
+    credPath: /crypto/admin/msp
+`
+
+func TestConfigDefaults(t *testing.T) {
+	flags := apputil.RegisterFlags(flag.NewFlagSet("test", flag.ContinueOnError))
+	config, err := flags.Config(apputil.DefaultConfig("basic"))
+	require.NoError(t, err)
+	require.Equal(t, "basic", config.Chaincode)
+	require.Equal(t, "mychannel", config.Channel)
+	require.Equal(t, "appUser", config.Identity)
+	require.Equal(t, "Org1MSP", config.Identities["appUser"].MSPID)
+	require.True(t, config.DiscoveryAsLocalhost)
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apputil")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(configYAML), 0600))
+
+	os.Setenv(apputil.EnvConfigFile, configFile)
+	defer os.Unsetenv(apputil.EnvConfigFile)
+	os.Setenv(apputil.EnvChaincode, "envchaincode")
+	defer os.Unsetenv(apputil.EnvChaincode)
+	os.Setenv(apputil.EnvIdentity, "admin")
+	defer os.Unsetenv(apputil.EnvIdentity)
+
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := apputil.RegisterFlags(flagSet)
+	require.NoError(t, flagSet.Parse([]string{"-chaincode", "flagchaincode", "-msp", "Org3MSP"}))
+
+	defaults := apputil.DefaultConfig("basic")
+	config, err := flags.Config(defaults)
+	require.NoError(t, err)
+	require.Equal(t, "filechannel", config.Channel)
+	require.Equal(t, "flagchaincode", config.Chaincode)
+	require.Equal(t, "admin", config.Identity)
+	require.False(t, config.DiscoveryAsLocalhost)
+	require.Equal(t, apputil.IdentityConfig{MSPID: "Org3MSP", CredPath: "/crypto/admin/msp"}, config.Identities["admin"])
+	require.Equal(t, "Org1MSP", config.Identities["appUser"].MSPID)
+	require.NotContains(t, defaults.Identities, "admin", "defaults must not be modified")
+}
+
+func TestConfigInvalid(t *testing.T) {
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := apputil.RegisterFlags(flagSet)
+	_, err := flags.Config(apputil.DefaultConfig(""))
+	require.EqualError(t, err, "chaincode must be set")
+
+	require.NoError(t, flagSet.Parse([]string{"-config", "missing.yaml"}))
+	_, err = flags.Config(apputil.DefaultConfig("basic"))
+	require.Error(t, err)
+}
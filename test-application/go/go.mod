@@ -0,0 +1,9 @@
+module github.com/hyperledger/fabric-samples/test-application/go
+
+go 1.14
+
+require (
+	github.com/hyperledger/fabric-sdk-go v1.0.0-rc1
+	github.com/stretchr/testify v1.5.1
+	gopkg.in/yaml.v2 v2.3.0
+)
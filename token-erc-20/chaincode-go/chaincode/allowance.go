@@ -0,0 +1,313 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Approval is an allowance of the owner to the spender, as returned by AllowancesOf
+type Approval struct {
+	Owner     string `json:"owner"`
+	Spender   string `json:"spender"`
+	Value     string `json:"value"`
+	ExpiresAt int64  `json:"expiresAt,omitempty"`
+}
+
+// allowanceRecord is the world state representation of an allowance. ExpiresAt is the Unix time,
+// in seconds, from which the allowance can no longer be spent, or 0 if it never expires.
+type allowanceRecord struct {
+	Value     string `json:"value"`
+	ExpiresAt int64  `json:"expiresAt,omitempty"`
+}
+
+// ApproveWithExpiry allows the spender to withdraw from the calling client's token account up to
+// the value amount, until the Unix time expiresAt in seconds. Expiry is judged against the timestamp
+// of the transactions, and expiresAt must be later than the timestamp of this transaction.
+// This function triggers an Approval event
+func (s *SmartContract) ApproveWithExpiry(ctx contractapi.TransactionContextInterface, spender string, value string, expiresAt int64) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Get ID of submitting client identity
+	owner, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	// Check that the contract is not paused and that no party is frozen
+	err = checkNotRestricted(ctx, owner, spender)
+	if err != nil {
+		return err
+	}
+
+	allowance, err := parseAmount(value)
+	if err != nil {
+		return fmt.Errorf("invalid allowance: %v", err)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if expiresAt <= now {
+		return fmt.Errorf("the expiry %d must be later than the transaction timestamp %d", expiresAt, now)
+	}
+
+	return setAllowance(ctx, owner, spender, allowance, expiresAt)
+}
+
+// IncreaseAllowance adds the added value to the allowance of the spender on the calling client's
+// token account, keeping its expiry. Unlike Approve, it cannot be raced by a TransferFrom of
+// the spender spending the previous allowance.
+// This function triggers an Approval event
+func (s *SmartContract) IncreaseAllowance(ctx contractapi.TransactionContextInterface, spender string, addedValue string) error {
+	return changeAllowance(ctx, spender, addedValue, addAmounts)
+}
+
+// DecreaseAllowance subtracts the subtracted value from the allowance of the spender on the calling
+// client's token account, keeping its expiry. It fails if the allowance is lower than the subtracted value.
+// This function triggers an Approval event
+func (s *SmartContract) DecreaseAllowance(ctx contractapi.TransactionContextInterface, spender string, subtractedValue string) error {
+	return changeAllowance(ctx, spender, subtractedValue, subAmounts)
+}
+
+// AllowancesOf returns the outstanding allowances of the owner, that are neither spent nor expired
+func (s *SmartContract) AllowancesOf(ctx contractapi.TransactionContextInterface, owner string) ([]*Approval, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(allowancePrefix, []string{owner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowances of %s: %v", owner, err)
+	}
+	defer resultsIterator.Close()
+
+	var now int64
+	approvals := []*Approval{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyParts) != 2 {
+			return nil, fmt.Errorf("invalid allowance key %s", queryResponse.Key)
+		}
+
+		record, err := parseAllowanceRecord(queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		allowance, err := parseAmount(record.Value)
+		if err != nil {
+			return nil, fmt.Errorf("corrupted amount stored under %s: %v", queryResponse.Key, err)
+		}
+		if allowance.Sign() == 0 {
+			continue
+		}
+		if record.ExpiresAt != 0 {
+			// Only read the transaction timestamp once an allowance has an expiry
+			if now == 0 {
+				now, err = txTime(ctx)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if record.ExpiresAt <= now {
+				continue
+			}
+		}
+
+		approvals = append(approvals, &Approval{
+			Owner:     keyParts[0],
+			Spender:   keyParts[1],
+			Value:     allowance.String(),
+			ExpiresAt: record.ExpiresAt,
+		})
+	}
+
+	return approvals, nil
+}
+
+// Helper Functions
+
+// changeAllowance applies the change to the allowance of the spender on the calling client's token account
+func changeAllowance(ctx contractapi.TransactionContextInterface, spender string, value string, change func(*big.Int, *big.Int) (*big.Int, error)) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Get ID of submitting client identity
+	owner, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	// Check that the contract is not paused and that no party is frozen
+	err = checkNotRestricted(ctx, owner, spender)
+	if err != nil {
+		return err
+	}
+
+	delta, err := parseAmount(value)
+	if err != nil {
+		return fmt.Errorf("invalid allowance change: %v", err)
+	}
+
+	currentAllowance, expiresAt, err := readAllowance(ctx, owner, spender)
+	if err != nil {
+		return err
+	}
+	expired, err := isExpired(ctx, expiresAt)
+	if err != nil {
+		return err
+	}
+	if expired {
+		return fmt.Errorf("the allowance of spender %s has expired, call Approve to set a new one", spender)
+	}
+
+	updatedAllowance, err := change(currentAllowance, delta)
+	if err != nil {
+		return fmt.Errorf("failed to change the allowance of spender %s: %v", spender, err)
+	}
+
+	return setAllowance(ctx, owner, spender, updatedAllowance, expiresAt)
+}
+
+// allowanceKeyOf returns the world state key of the allowance of the owner to the spender
+func allowanceKeyOf(ctx contractapi.TransactionContextInterface, owner string, spender string) (string, error) {
+	allowanceKey, err := ctx.GetStub().CreateCompositeKey(allowancePrefix, []string{owner, spender})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", allowancePrefix, err)
+	}
+
+	return allowanceKey, nil
+}
+
+// readAllowance returns the allowance of the owner to the spender and its expiry, regardless of
+// whether it has expired. A missing allowance is read as zero without expiry.
+func readAllowance(ctx contractapi.TransactionContextInterface, owner string, spender string) (*big.Int, int64, error) {
+	allowanceKey, err := allowanceKeyOf(ctx, owner, spender)
+	if err != nil {
+		return nil, 0, err
+	}
+	allowanceBytes, err := ctx.GetStub().GetState(allowanceKey)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s from world state: %v", allowanceKey, err)
+	}
+	if allowanceBytes == nil {
+		return new(big.Int), 0, nil
+	}
+
+	record, err := parseAllowanceRecord(allowanceKey, allowanceBytes)
+	if err != nil {
+		return nil, 0, err
+	}
+	allowance, err := parseAmount(record.Value)
+	if err != nil {
+		return nil, 0, fmt.Errorf("corrupted amount stored under %s: %v", allowanceKey, err)
+	}
+
+	return allowance, record.ExpiresAt, nil
+}
+
+// parseAllowanceRecord parses an allowance read from the world state. Allowances stored before
+// expiries were introduced are plain decimal strings, and are read as allowances without expiry.
+func parseAllowanceRecord(allowanceKey string, allowanceBytes []byte) (*allowanceRecord, error) {
+	if !strings.HasPrefix(string(allowanceBytes), "{") {
+		return &allowanceRecord{Value: string(allowanceBytes)}, nil
+	}
+
+	var record allowanceRecord
+	err := json.Unmarshal(allowanceBytes, &record)
+	if err != nil {
+		return nil, fmt.Errorf("corrupted allowance stored under %s: %v", allowanceKey, err)
+	}
+
+	return &record, nil
+}
+
+// setAllowance stores the allowance of the owner to the spender and emits the Approval event
+func setAllowance(ctx contractapi.TransactionContextInterface, owner string, spender string, allowance *big.Int, expiresAt int64) error {
+	allowanceKey, err := allowanceKeyOf(ctx, owner, spender)
+	if err != nil {
+		return err
+	}
+	err = putAllowance(ctx, allowanceKey, allowance, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	// Emit the Approval event
+	err = emitApproval(ctx, owner, spender, allowance, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("client %s approved a withdrawal allowance of %s for spender %s", owner, allowance, spender)
+
+	return nil
+}
+
+// putAllowance stores the allowance under the key
+func putAllowance(ctx contractapi.TransactionContextInterface, allowanceKey string, allowance *big.Int, expiresAt int64) error {
+	allowanceJSON, err := json.Marshal(allowanceRecord{Value: allowance.String(), ExpiresAt: expiresAt})
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(allowanceKey, allowanceJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put %s to world state: %v", allowanceKey, err)
+	}
+
+	return nil
+}
+
+// isExpired returns true when the expiry is set and the transaction timestamp has reached it
+func isExpired(ctx contractapi.TransactionContextInterface, expiresAt int64) (bool, error) {
+	if expiresAt == 0 {
+		return false, nil
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return now >= expiresAt, nil
+}
+
+// txTime returns the timestamp of the transaction as a Unix time in seconds. The transaction
+// timestamp is set by the client and is the same on every endorsing peer, unlike the local clock.
+func txTime(ctx contractapi.TransactionContextInterface) (int64, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	txTime, err := ptypes.Timestamp(txTimestamp)
+	if err != nil {
+		return 0, fmt.Errorf("invalid transaction timestamp: %v", err)
+	}
+
+	return txTime.Unix(), nil
+}
@@ -0,0 +1,104 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncreaseAndDecreaseAllowance(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	err := worldState.Submit(token.IncreaseAllowance(minterContext, recipientID, "100"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Approval", `{"owner":"minter","spender":"recipient","value":"100","txID":"tx1"}`)
+
+	err = worldState.Submit(token.IncreaseAllowance(minterContext, recipientID, "50"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Approval", `{"owner":"minter","spender":"recipient","value":"150","txID":"tx1"}`)
+
+	err = worldState.Submit(token.DecreaseAllowance(minterContext, recipientID, "30"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Approval", `{"owner":"minter","spender":"recipient","value":"120","txID":"tx1"}`)
+
+	err = worldState.Submit(token.DecreaseAllowance(minterContext, recipientID, "121"))
+	require.EqualError(t, err, "failed to change the allowance of spender recipient: arithmetic underflow: 120 - 121 is negative")
+	err = worldState.Submit(token.IncreaseAllowance(minterContext, recipientID, "x"))
+	require.EqualError(t, err, "invalid allowance change: invalid amount x: amount must be a non-negative decimal integer")
+
+	allowance, err := token.Allowance(minterContext, minterID, recipientID)
+	require.NoError(t, err)
+	require.Equal(t, "120", allowance)
+}
+
+func TestApproveWithExpiry(t *testing.T) {
+	token, worldState := newToken(t)
+	now := time.Unix(1600000000, 0)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	spenderContext := mocks.NewTransactionContext(worldState, recipientID, "Org2MSP")
+
+	err := worldState.Submit(token.ApproveWithExpiry(minterContext, recipientID, "300", now.Unix()))
+	require.EqualError(t, err, "the expiry 1600000000 must be later than the transaction timestamp 1600000000")
+
+	err = worldState.Submit(token.ApproveWithExpiry(minterContext, recipientID, "300", now.Unix()+100))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Approval", `{"owner":"minter","spender":"recipient","value":"300","expiresAt":1600000100,"txID":"tx1"}`)
+
+	// The allowance can be spent and increased until it expires, and keeps its expiry
+	worldState.StartTransaction("tx2", now.Add(99*time.Second))
+	err = worldState.Submit(token.TransferFrom(spenderContext, minterID, recipientID, "100"))
+	require.NoError(t, err)
+	err = worldState.Submit(token.IncreaseAllowance(minterContext, recipientID, "50"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Approval", `{"owner":"minter","spender":"recipient","value":"250","expiresAt":1600000100,"txID":"tx2"}`)
+
+	// Expiry is judged against the transaction timestamp
+	worldState.StartTransaction("tx3", now.Add(100*time.Second))
+	err = worldState.Submit(token.TransferFrom(spenderContext, minterID, recipientID, "1"))
+	require.EqualError(t, err, "the allowance of spender recipient has expired")
+	err = worldState.Submit(token.IncreaseAllowance(minterContext, recipientID, "50"))
+	require.EqualError(t, err, "the allowance of spender recipient has expired, call Approve to set a new one")
+	allowance, err := token.Allowance(minterContext, minterID, recipientID)
+	require.NoError(t, err)
+	require.Equal(t, "0", allowance)
+
+	// Approve sets a new allowance without expiry
+	err = worldState.Submit(token.Approve(minterContext, recipientID, "10"))
+	require.NoError(t, err)
+	err = worldState.Submit(token.TransferFrom(spenderContext, minterID, recipientID, "10"))
+	require.NoError(t, err)
+	requireBalances(t, token, worldState, map[string]string{minterID: "890", recipientID: "110"})
+}
+
+func TestAllowancesOf(t *testing.T) {
+	token, worldState := newToken(t)
+	now := time.Unix(1600000000, 0)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	require.NoError(t, worldState.Submit(token.Approve(minterContext, recipientID, "100")))
+	require.NoError(t, worldState.Submit(token.ApproveWithExpiry(minterContext, "other", "200", now.Unix()+100)))
+	require.NoError(t, worldState.Submit(token.Approve(minterContext, "revoked", "0")))
+	require.NoError(t, worldState.Submit(token.Approve(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), minterID, "300")))
+
+	approvals, err := token.AllowancesOf(minterContext, minterID)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Approval{
+		{Owner: minterID, Spender: "other", Value: "200", ExpiresAt: now.Unix() + 100},
+		{Owner: minterID, Spender: recipientID, Value: "100"},
+	}, approvals)
+
+	worldState.StartTransaction("tx2", now.Add(100*time.Second))
+	approvals, err = token.AllowancesOf(minterContext, minterID)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Approval{
+		{Owner: minterID, Spender: recipientID, Value: "100"},
+	}, approvals)
+
+	approvals, err = token.AllowancesOf(minterContext, "other")
+	require.NoError(t, err)
+	require.Empty(t, approvals)
+}
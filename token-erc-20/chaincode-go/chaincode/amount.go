@@ -0,0 +1,95 @@
+package chaincode
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxAmount is the largest balance, allowance or total supply, the maximum value of an
+// ERC-20 uint256. Operations exceeding it are rejected as overflows.
+var maxAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// parseAmount parses a token amount given as a decimal string of digits
+func parseAmount(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("amount must not be empty")
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid amount %s: amount must be a non-negative decimal integer", value)
+		}
+	}
+
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %s: amount must be a non-negative decimal integer", value)
+	}
+	if amount.Cmp(maxAmount) > 0 {
+		return nil, fmt.Errorf("invalid amount %s: amount exceeds the maximum of %s", value, maxAmount)
+	}
+
+	return amount, nil
+}
+
+// parsePositiveAmount parses a token amount that must be greater than zero
+func parsePositiveAmount(value string) (*big.Int, error) {
+	amount, err := parseAmount(value)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() == 0 {
+		return nil, fmt.Errorf("amount must be a positive integer")
+	}
+
+	return amount, nil
+}
+
+// addAmounts returns a + b, or an error when the sum exceeds the maximum amount
+func addAmounts(a *big.Int, b *big.Int) (*big.Int, error) {
+	sum := new(big.Int).Add(a, b)
+	if sum.Cmp(maxAmount) > 0 {
+		return nil, fmt.Errorf("arithmetic overflow: %s + %s exceeds the maximum amount", a, b)
+	}
+
+	return sum, nil
+}
+
+// subAmounts returns a - b, or an error when the difference is negative
+func subAmounts(a *big.Int, b *big.Int) (*big.Int, error) {
+	if a.Cmp(b) < 0 {
+		return nil, fmt.Errorf("arithmetic underflow: %s - %s is negative", a, b)
+	}
+
+	return new(big.Int).Sub(a, b), nil
+}
+
+// readAmount reads the amount stored under the key. A missing key is read as zero
+// and reported with exists set to false, while a corrupted amount is an error.
+func readAmount(ctx contractapi.TransactionContextInterface, key string) (amount *big.Int, exists bool, err error) {
+	amountBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s from world state: %v", key, err)
+	}
+	if amountBytes == nil {
+		return new(big.Int), false, nil
+	}
+
+	amount, err = parseAmount(string(amountBytes))
+	if err != nil {
+		return nil, false, fmt.Errorf("corrupted amount stored under %s: %v", key, err)
+	}
+
+	return amount, true, nil
+}
+
+// putAmount stores the amount under the key as a decimal string
+func putAmount(ctx contractapi.TransactionContextInterface, key string, amount *big.Int) error {
+	err := ctx.GetStub().PutState(key, []byte(amount.String()))
+	if err != nil {
+		return fmt.Errorf("failed to put %s to world state: %v", key, err)
+	}
+
+	return nil
+}
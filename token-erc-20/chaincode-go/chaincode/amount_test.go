@@ -0,0 +1,114 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// maxAmount is 2^256 - 1, the largest amount the contract accepts
+const maxAmount = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+func TestLargeAmounts(t *testing.T) {
+	worldState := prepWorldState(t, nil)
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	// One billion tokens with 18 decimals does not fit in an int64
+	err := worldState.Submit(token.Mint(transactionContext, "1000000000000000000000000000"))
+	require.NoError(t, err)
+	err = worldState.Submit(token.Transfer(transactionContext, recipientID, "999999999999999999999999999"))
+	require.NoError(t, err)
+
+	requireBalances(t, &token, worldState, map[string]string{
+		minterID:      "1",
+		recipientID:   "999999999999999999999999999",
+		"totalSupply": "1000000000000000000000000000",
+	})
+}
+
+func TestInvalidAmounts(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{minterBalanceKey: "100"})
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Mint(transactionContext, "0"))
+	require.EqualError(t, err, "invalid mint amount: amount must be a positive integer")
+	err = worldState.Submit(token.Mint(transactionContext, "-5"))
+	require.EqualError(t, err, "invalid mint amount: invalid amount -5: amount must be a non-negative decimal integer")
+	err = worldState.Submit(token.Burn(transactionContext, "1.5"))
+	require.EqualError(t, err, "invalid burn amount: invalid amount 1.5: amount must be a non-negative decimal integer")
+	err = worldState.Submit(token.Transfer(transactionContext, recipientID, ""))
+	require.EqualError(t, err, "invalid transfer amount: amount must not be empty")
+	err = worldState.Submit(token.Approve(transactionContext, recipientID, "115792089237316195423570985008687907853269984665640564039457584007913129639936"))
+	require.EqualError(t, err, "invalid allowance: invalid amount 115792089237316195423570985008687907853269984665640564039457584007913129639936: amount exceeds the maximum of "+maxAmount)
+}
+
+func TestAmountOverflow(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{
+		minterBalanceKey:    maxAmount,
+		recipientBalanceKey: "1",
+		"totalSupply":       maxAmount,
+	})
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Mint(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), "1"))
+	require.EqualError(t, err, "arithmetic overflow: "+maxAmount+" + 1 exceeds the maximum amount")
+
+	err = worldState.Submit(token.Transfer(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), minterID, "1"))
+	require.EqualError(t, err, "failed to transfer: arithmetic overflow: "+maxAmount+" + 1 exceeds the maximum amount")
+	requireBalances(t, &token, worldState, map[string]string{minterID: maxAmount, recipientID: "1", "totalSupply": maxAmount})
+}
+
+func TestAmountUnderflow(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{
+		minterBalanceKey: "100",
+		"totalSupply":    "100",
+		"\x00allowance\x00minter\x00recipient\x00": "50",
+	})
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Burn(transactionContext, "101"))
+	require.EqualError(t, err, "minter account minter has insufficient funds: arithmetic underflow: 100 - 101 is negative")
+	err = worldState.Submit(token.Transfer(transactionContext, recipientID, "101"))
+	require.EqualError(t, err, "failed to transfer: client account minter has insufficient funds")
+
+	err = worldState.Submit(token.TransferFrom(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), minterID, recipientID, "51"))
+	require.EqualError(t, err, "spender does not have enough allowance for transfer")
+	requireBalances(t, &token, worldState, map[string]string{minterID: "100", "totalSupply": "100"})
+	allowance, err := token.Allowance(transactionContext, minterID, recipientID)
+	require.NoError(t, err)
+	require.Equal(t, "50", allowance)
+}
+
+func TestSelfTransfer(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{minterBalanceKey: "100"})
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Transfer(transactionContext, minterID, "40"))
+	require.NoError(t, err)
+	requireBalances(t, &token, worldState, map[string]string{minterID: "100"})
+
+	err = worldState.Submit(token.Transfer(transactionContext, minterID, "101"))
+	require.EqualError(t, err, "failed to transfer: client account minter has insufficient funds")
+}
+
+func TestCorruptedAmount(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{
+		minterBalanceKey: "12abc",
+		"totalSupply":    "-3",
+	})
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	_, err := token.BalanceOf(transactionContext, minterID)
+	require.EqualError(t, err, "corrupted balance of account minter: invalid amount 12abc: amount must be a non-negative decimal integer")
+	_, err = token.TotalSupply(transactionContext)
+	require.EqualError(t, err, "failed to retrieve total token supply: corrupted amount stored under totalSupply: invalid amount -3: amount must be a non-negative decimal integer")
+	err = worldState.Submit(token.Transfer(transactionContext, recipientID, "1"))
+	require.EqualError(t, err, "failed to transfer: failed to read client account minter: corrupted balance of account minter: invalid amount 12abc: amount must be a non-negative decimal integer")
+}
@@ -0,0 +1,268 @@
+package chaincode
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const balancePrefix = "balance"
+
+// Holder is an account holding tokens, as returned by Holders
+type Holder struct {
+	Account string `json:"account"`
+	Balance string `json:"balance"`
+}
+
+// HoldersQueryResult structure used for returning paginated holders and metadata
+type HoldersQueryResult struct {
+	Records             []*Holder `json:"records"`
+	FetchedRecordsCount int32     `json:"fetchedRecordsCount"`
+	Bookmark            string    `json:"bookmark"`
+}
+
+// Holders returns a page of the accounts holding tokens, in the order of their account IDs.
+// The page is read from pageSize balances, starting at the bookmark returned with the previous page,
+// or at the first balance when bookmark is empty. Accounts with a zero balance are left out,
+// so a page can hold fewer than pageSize records while more pages remain. The last page
+// is returned with an empty bookmark.
+func (s *SmartContract) Holders(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*HoldersQueryResult, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(balancePrefix, []string{}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balances: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	holders := []*Holder{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyParts) != 1 {
+			return nil, fmt.Errorf("invalid balance key %s", queryResponse.Key)
+		}
+
+		balance, err := parseAmount(string(queryResponse.Value))
+		if err != nil {
+			return nil, fmt.Errorf("corrupted balance of account %s: %v", keyParts[0], err)
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+
+		holders = append(holders, &Holder{Account: keyParts[0], Balance: balance.String()})
+	}
+
+	return &HoldersQueryResult{
+		Records:             holders,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}, nil
+}
+
+// BalanceMigrationResult structure used for returning the outcome of a MigrateBalances page
+type BalanceMigrationResult struct {
+	Records             []*Holder `json:"records"`
+	ScannedRecordsCount int32     `json:"scannedRecordsCount"`
+	NextStartKey        string    `json:"nextStartKey"`
+}
+
+// MigrateBalances moves a page of the balances stored by earlier versions of the contract, under
+// simple keys equal to the account IDs, to the balance composite keys read by this version.
+// The page is read from pageSize simple keys, starting at the next start key returned with the
+// previous page, or at the first key when startKey is empty. A legacy balance is added to the
+// balance the account got since the upgrade, if any, and its simple key is deleted. The migrated
+// accounts are returned with their balances, and the last page with an empty next start key.
+// Until their migration, reading or changing a legacy balance fails, so the migration should run
+// right after the upgrade.
+// Only a client holding the ADMIN role can migrate balances.
+func (s *SmartContract) MigrateBalances(ctx contractapi.TransactionContextInterface, pageSize int32, startKey string) (*BalanceMigrationResult, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = checkRole(ctx, AdminRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	// a range query only returns simple keys, which hold the contract options and the legacy balances.
+	// Paginated queries are not allowed in update transactions, so the page is bounded by hand.
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy balances: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	result := &BalanceMigrationResult{Records: []*Holder{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if result.ScannedRecordsCount == pageSize {
+			result.NextStartKey = queryResponse.Key
+			break
+		}
+		result.ScannedRecordsCount++
+
+		account := queryResponse.Key
+		if isContractKey(account) {
+			continue
+		}
+
+		legacyBalance, err := parseAmount(string(queryResponse.Value))
+		if err != nil {
+			return nil, fmt.Errorf("corrupted legacy balance of account %s: %v", account, err)
+		}
+		currentBalance, _, err := readMigratedBalance(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		balance, err := addAmounts(currentBalance, legacyBalance)
+		if err != nil {
+			return nil, err
+		}
+
+		err = putBalance(ctx, account, balance)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().DelState(account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete legacy balance of %s from world state: %v", account, err)
+		}
+
+		log.Printf("legacy balance of account %s migrated, balance updated from %s to %s", account, currentBalance, balance)
+		result.Records = append(result.Records, &Holder{Account: account, Balance: balance.String()})
+	}
+
+	return result, nil
+}
+
+// Helper Functions
+
+// isContractKey returns true when the simple key holds a value of the contract rather than a legacy balance
+func isContractKey(key string) bool {
+	switch key {
+	case nameKey, symbolKey, decimalsKey, totalSupplyKey, pausedKey, snapshotIDKey:
+		return true
+	}
+
+	return false
+}
+
+// balanceKeyOf returns the world state key of the balance of the account
+func balanceKeyOf(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	if account == "" {
+		return "", fmt.Errorf("account must not be empty")
+	}
+
+	balanceKey, err := ctx.GetStub().CreateCompositeKey(balancePrefix, []string{account})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", balancePrefix, err)
+	}
+
+	return balanceKey, nil
+}
+
+// readBalance reads the balance of the account. An account without balance is read as zero
+// and reported with exists set to false, while a corrupted balance is an error. A balance still
+// stored in the legacy format is an error as well, until MigrateBalances moves it.
+func readBalance(ctx contractapi.TransactionContextInterface, account string) (balance *big.Int, exists bool, err error) {
+	balance, exists, err = readMigratedBalance(ctx, account)
+	if err != nil || exists || isContractKey(account) {
+		return balance, exists, err
+	}
+
+	legacyBalanceBytes, err := ctx.GetStub().GetState(account)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read legacy balance of %s from world state: %v", account, err)
+	}
+	if legacyBalanceBytes != nil {
+		return nil, false, fmt.Errorf("balance of account %s is stored in the legacy format, MigrateBalances must be run first", account)
+	}
+
+	return balance, false, nil
+}
+
+// readMigratedBalance reads the balance of the account from its composite key, ignoring
+// any legacy balance of the account
+func readMigratedBalance(ctx contractapi.TransactionContextInterface, account string) (balance *big.Int, exists bool, err error) {
+	balanceKey, err := balanceKeyOf(ctx, account)
+	if err != nil {
+		return nil, false, err
+	}
+	balanceBytes, err := ctx.GetStub().GetState(balanceKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read balance of %s from world state: %v", account, err)
+	}
+	if balanceBytes == nil {
+		return new(big.Int), false, nil
+	}
+
+	balance, err = parseAmount(string(balanceBytes))
+	if err != nil {
+		return nil, false, fmt.Errorf("corrupted balance of account %s: %v", account, err)
+	}
+
+	return balance, true, nil
+}
+
+// putBalance stores the balance of the account, after recording its previous balance
+// in the latest snapshot if it is the first change since the snapshot was taken
+func putBalance(ctx contractapi.TransactionContextInterface, account string, balance *big.Int) error {
+	balanceKey, err := balanceKeyOf(ctx, account)
+	if err != nil {
+		return err
+	}
+	err = recordSnapshot(ctx, balanceSnapshotPrefix, []string{account}, balanceKey)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(balanceKey, []byte(balance.String()))
+	if err != nil {
+		return fmt.Errorf("failed to put balance of %s to world state: %v", account, err)
+	}
+
+	return nil
+}
+
+// putTotalSupply stores the total supply, after recording its previous value in the latest
+// snapshot if it is the first change since the snapshot was taken
+func putTotalSupply(ctx contractapi.TransactionContextInterface, totalSupply *big.Int) error {
+	err := recordSnapshot(ctx, supplySnapshotPrefix, []string{}, totalSupplyKey)
+	if err != nil {
+		return err
+	}
+
+	return putAmount(ctx, totalSupplyKey, totalSupply)
+}
@@ -0,0 +1,101 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolders(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	for account, amount := range map[string]string{"a": "10", "b": "20", "c": "30", "zero": "0"} {
+		require.NoError(t, worldState.Submit(token.Transfer(minterContext, account, amount)))
+	}
+
+	result, err := token.Holders(minterContext, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Holder{{Account: "a", Balance: "10"}, {Account: "b", Balance: "20"}}, result.Records)
+	require.Equal(t, int32(2), result.FetchedRecordsCount)
+	require.Equal(t, "\x00balance\x00c\x00", result.Bookmark)
+
+	result, err = token.Holders(minterContext, 2, result.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Holder{{Account: "c", Balance: "30"}, {Account: minterID, Balance: "940"}}, result.Records)
+
+	// Accounts with a zero balance are left out
+	result, err = token.Holders(minterContext, 2, result.Bookmark)
+	require.NoError(t, err)
+	require.Empty(t, result.Records)
+	require.Equal(t, int32(1), result.FetchedRecordsCount)
+	require.Equal(t, "", result.Bookmark)
+
+	_, err = token.Holders(minterContext, 0, "")
+	require.EqualError(t, err, "page size must be a positive integer")
+}
+
+func TestMigrateBalances(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{
+		"symbol":         "SYM",
+		"decimals":       "2",
+		"totalSupply":    "135",
+		"a":              "10",
+		"b":              "20",
+		"minter":         "100",
+		minterBalanceKey: "5",
+	})
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	// Legacy balances are not read as zero before their migration
+	_, err := token.BalanceOf(minterContext, "a")
+	require.EqualError(t, err, "balance of account a is stored in the legacy format, MigrateBalances must be run first")
+	err = token.Transfer(mocks.NewTransactionContext(worldState, "b", "Org1MSP"), recipientID, "1")
+	require.EqualError(t, worldState.Submit(err), "failed to transfer: failed to read client account b: balance of account b is stored in the legacy format, MigrateBalances must be run first")
+
+	_, err = token.MigrateBalances(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), 3, "")
+	require.EqualError(t, worldState.Submit(err), "client is not authorized: the role ADMIN is required")
+
+	result, err := token.MigrateBalances(minterContext, 3, "")
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, &chaincode.BalanceMigrationResult{
+		Records:             []*chaincode.Holder{{Account: "a", Balance: "10"}, {Account: "b", Balance: "20"}},
+		ScannedRecordsCount: 3,
+		NextStartKey:        "minter",
+	}, result)
+
+	// A legacy balance is added to the balance the account got since the upgrade
+	result, err = token.MigrateBalances(minterContext, 3, result.NextStartKey)
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, &chaincode.BalanceMigrationResult{
+		Records:             []*chaincode.Holder{{Account: minterID, Balance: "105"}},
+		ScannedRecordsCount: 3,
+		NextStartKey:        "totalSupply",
+	}, result)
+
+	result, err = token.MigrateBalances(minterContext, 3, result.NextStartKey)
+	require.NoError(t, worldState.Submit(err))
+	require.Empty(t, result.Records)
+	require.Equal(t, int32(1), result.ScannedRecordsCount)
+	require.Equal(t, "", result.NextStartKey)
+
+	requireBalances(t, &token, worldState, map[string]string{"a": "10", "b": "20", minterID: "105", "totalSupply": "135"})
+	holders, err := token.Holders(minterContext, 10, "")
+	require.NoError(t, err)
+	require.Len(t, holders.Records, 3)
+	for _, legacyKey := range []string{"a", "b", "minter"} {
+		require.NotContains(t, worldState.Keys(), legacyKey)
+	}
+
+	// Migrating again finds no legacy balance
+	result, err = token.MigrateBalances(minterContext, 10, "")
+	require.NoError(t, worldState.Submit(err))
+	require.Empty(t, result.Records)
+
+	require.NoError(t, worldState.PutState("c", []byte("abc")))
+	worldState.Commit()
+	_, err = token.MigrateBalances(minterContext, 10, "")
+	require.EqualError(t, worldState.Submit(err), "corrupted legacy balance of account c: invalid amount abc: amount must be a non-negative decimal integer")
+}
@@ -0,0 +1,71 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define names of the events emitted by the contract
+const transferEventName = "Transfer"
+const approvalEventName = "Approval"
+
+// zeroAddress is the account tokens are minted from and burned to in Transfer events
+const zeroAddress = "0x0"
+
+// TransferEvent is the payload of the Transfer event, emitted when tokens are moved from one
+// account to another. Minted tokens come from the zero address 0x0 and burned tokens go to it.
+type TransferEvent struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value string `json:"value"`
+	TxID  string `json:"txID"`
+}
+
+// ApprovalEvent is the payload of the Approval event, emitted when an owner sets the
+// amount of tokens a spender is allowed to withdraw from its account, and the Unix time
+// at which the allowance expires, if any
+type ApprovalEvent struct {
+	Owner     string `json:"owner"`
+	Spender   string `json:"spender"`
+	Value     string `json:"value"`
+	ExpiresAt int64  `json:"expiresAt,omitempty"`
+	TxID      string `json:"txID"`
+}
+
+// emitTransfer sets the Transfer event of the transaction
+func emitTransfer(ctx contractapi.TransactionContextInterface, from string, to string, value *big.Int) error {
+	return setEvent(ctx, transferEventName, TransferEvent{
+		From:  from,
+		To:    to,
+		Value: value.String(),
+		TxID:  ctx.GetStub().GetTxID(),
+	})
+}
+
+// emitApproval sets the Approval event of the transaction
+func emitApproval(ctx contractapi.TransactionContextInterface, owner string, spender string, value *big.Int, expiresAt int64) error {
+	return setEvent(ctx, approvalEventName, ApprovalEvent{
+		Owner:     owner,
+		Spender:   spender,
+		Value:     value.String(),
+		ExpiresAt: expiresAt,
+		TxID:      ctx.GetStub().GetTxID(),
+	})
+}
+
+// setEvent sets the JSON encoding of the payload as the event of the transaction
+func setEvent(ctx contractapi.TransactionContextInterface, name string, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent(name, payloadJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
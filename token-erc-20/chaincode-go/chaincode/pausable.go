@@ -0,0 +1,256 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define key names for the pause state
+const pausedKey = "paused"
+
+// Define objectType names for prefix
+const frozenPrefix = "frozen"
+
+// Define names of the pause and freeze events
+const pausedEventName = "Paused"
+const unpausedEventName = "Unpaused"
+const accountFrozenEventName = "AccountFrozen"
+const accountUnfrozenEventName = "AccountUnfrozen"
+
+// PauseEvent is the payload of the Paused and Unpaused events
+type PauseEvent struct {
+	Sender string `json:"sender"`
+	TxID   string `json:"txID"`
+}
+
+// FreezeEvent is the payload of the AccountFrozen and AccountUnfrozen events
+type FreezeEvent struct {
+	Account string `json:"account"`
+	Sender  string `json:"sender"`
+	TxID    string `json:"txID"`
+}
+
+// Pause halts Mint, Burn, Transfer, TransferFrom and Approve until the contract is unpaused.
+// Only a client holding the ADMIN role can pause the contract.
+// This function triggers a Paused event
+func (s *SmartContract) Pause(ctx contractapi.TransactionContextInterface) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
+
+	sender, err := checkRole(ctx, AdminRole)
+	if err != nil {
+		return err
+	}
+
+	paused, err := isPaused(ctx)
+	if err != nil {
+		return err
+	}
+	if paused {
+		return fmt.Errorf("the contract is already paused")
+	}
+
+	err = ctx.GetStub().PutState(pausedKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put pause state to world state: %v", err)
+	}
+
+	return setEvent(ctx, pausedEventName, PauseEvent{sender, ctx.GetStub().GetTxID()})
+}
+
+// Unpause resumes the operations halted by Pause.
+// Only a client holding the ADMIN role can unpause the contract.
+// This function triggers an Unpaused event
+func (s *SmartContract) Unpause(ctx contractapi.TransactionContextInterface) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
+
+	sender, err := checkRole(ctx, AdminRole)
+	if err != nil {
+		return err
+	}
+
+	paused, err := isPaused(ctx)
+	if err != nil {
+		return err
+	}
+	if !paused {
+		return fmt.Errorf("the contract is not paused")
+	}
+
+	err = ctx.GetStub().DelState(pausedKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete pause state from world state: %v", err)
+	}
+
+	return setEvent(ctx, unpausedEventName, PauseEvent{sender, ctx.GetStub().GetTxID()})
+}
+
+// Paused returns true when the contract is paused
+func (s *SmartContract) Paused(ctx contractapi.TransactionContextInterface) (bool, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return isPaused(ctx)
+}
+
+// FreezeAccount prevents the account from sending, receiving, minting, burning and approving tokens,
+// and from spending allowances, until it is unfrozen.
+// Only a client holding the ADMIN role can freeze accounts.
+// This function triggers an AccountFrozen event
+func (s *SmartContract) FreezeAccount(ctx contractapi.TransactionContextInterface, account string) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
+
+	sender, err := checkRole(ctx, AdminRole)
+	if err != nil {
+		return err
+	}
+
+	frozenKey, err := frozenKeyOf(ctx, account)
+	if err != nil {
+		return err
+	}
+	frozen, err := isFrozen(ctx, account)
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return fmt.Errorf("account %s is already frozen", account)
+	}
+
+	err = ctx.GetStub().PutState(frozenKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put frozen account to world state: %v", err)
+	}
+
+	return setEvent(ctx, accountFrozenEventName, FreezeEvent{account, sender, ctx.GetStub().GetTxID()})
+}
+
+// UnfreezeAccount lifts the restrictions set on the account by FreezeAccount.
+// Only a client holding the ADMIN role can unfreeze accounts.
+// This function triggers an AccountUnfrozen event
+func (s *SmartContract) UnfreezeAccount(ctx contractapi.TransactionContextInterface, account string) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
+
+	sender, err := checkRole(ctx, AdminRole)
+	if err != nil {
+		return err
+	}
+
+	frozenKey, err := frozenKeyOf(ctx, account)
+	if err != nil {
+		return err
+	}
+	frozen, err := isFrozen(ctx, account)
+	if err != nil {
+		return err
+	}
+	if !frozen {
+		return fmt.Errorf("account %s is not frozen", account)
+	}
+
+	err = ctx.GetStub().DelState(frozenKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete frozen account from world state: %v", err)
+	}
+
+	return setEvent(ctx, accountUnfrozenEventName, FreezeEvent{account, sender, ctx.GetStub().GetTxID()})
+}
+
+// IsFrozen returns true when the account is frozen
+func (s *SmartContract) IsFrozen(ctx contractapi.TransactionContextInterface, account string) (bool, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return isFrozen(ctx, account)
+}
+
+// Helper Functions
+
+// frozenKeyOf returns the world state key recording that the account is frozen
+func frozenKeyOf(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	if account == "" {
+		return "", fmt.Errorf("account must not be empty")
+	}
+
+	frozenKey, err := ctx.GetStub().CreateCompositeKey(frozenPrefix, []string{account})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", frozenPrefix, err)
+	}
+
+	return frozenKey, nil
+}
+
+// isPaused returns true when the contract is paused
+func isPaused(ctx contractapi.TransactionContextInterface) (bool, error) {
+	pausedBytes, err := ctx.GetStub().GetState(pausedKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read pause state from world state: %v", err)
+	}
+
+	return pausedBytes != nil, nil
+}
+
+// isFrozen returns true when the account is frozen
+func isFrozen(ctx contractapi.TransactionContextInterface, account string) (bool, error) {
+	frozenKey, err := frozenKeyOf(ctx, account)
+	if err != nil {
+		return false, err
+	}
+	frozenBytes, err := ctx.GetStub().GetState(frozenKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read frozen account from world state: %v", err)
+	}
+
+	return frozenBytes != nil, nil
+}
+
+// checkNotRestricted returns an error when the contract is paused or any of the accounts is frozen
+func checkNotRestricted(ctx contractapi.TransactionContextInterface, accounts ...string) error {
+	paused, err := isPaused(ctx)
+	if err != nil {
+		return err
+	}
+	if paused {
+		return fmt.Errorf("the contract is paused")
+	}
+
+	for _, account := range accounts {
+		frozen, err := isFrozen(ctx, account)
+		if err != nil {
+			return err
+		}
+		if frozen {
+			return fmt.Errorf("account %s is frozen", account)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPause(t *testing.T) {
+	worldState := prepWorldState(t, nil)
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Pause(transactionContext))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Paused", `{"sender":"minter","txID":"tx1"}`)
+
+	paused, err := token.Paused(transactionContext)
+	require.NoError(t, err)
+	require.True(t, paused)
+
+	err = worldState.Submit(token.Pause(transactionContext))
+	require.EqualError(t, err, "the contract is already paused")
+
+	worldState = prepWorldState(t, nil)
+	err = worldState.Submit(token.Pause(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP")))
+	require.EqualError(t, err, "client is not authorized: the role ADMIN is required")
+	require.NotContains(t, worldState.Keys(), "paused")
+}
+
+func TestUnpause(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{"paused": "\x00"})
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Unpause(transactionContext))
+	require.NoError(t, err)
+	require.NotContains(t, worldState.Keys(), "paused")
+	requireLastEvent(t, worldState, "Unpaused", `{"sender":"minter","txID":"tx1"}`)
+
+	err = worldState.Submit(token.Unpause(transactionContext))
+	require.EqualError(t, err, "the contract is not paused")
+}
+
+func TestFreezeAccount(t *testing.T) {
+	worldState := prepWorldState(t, nil)
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.FreezeAccount(transactionContext, recipientID))
+	require.NoError(t, err)
+	require.Contains(t, worldState.Keys(), "\x00frozen\x00recipient\x00")
+	requireLastEvent(t, worldState, "AccountFrozen", `{"account":"recipient","sender":"minter","txID":"tx1"}`)
+
+	frozen, err := token.IsFrozen(transactionContext, recipientID)
+	require.NoError(t, err)
+	require.True(t, frozen)
+
+	err = worldState.Submit(token.FreezeAccount(transactionContext, recipientID))
+	require.EqualError(t, err, "account recipient is already frozen")
+
+	err = worldState.Submit(token.FreezeAccount(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), minterID))
+	require.EqualError(t, err, "client is not authorized: the role ADMIN is required")
+}
+
+func TestUnfreezeAccount(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{"\x00frozen\x00recipient\x00": "\x00"})
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.UnfreezeAccount(transactionContext, recipientID))
+	require.NoError(t, err)
+	require.NotContains(t, worldState.Keys(), "\x00frozen\x00recipient\x00")
+	requireLastEvent(t, worldState, "AccountUnfrozen", `{"account":"recipient","sender":"minter","txID":"tx1"}`)
+
+	err = worldState.Submit(token.UnfreezeAccount(transactionContext, recipientID))
+	require.EqualError(t, err, "account recipient is not frozen")
+}
+
+func TestPausedContractBlocksOperations(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{
+		"paused":         "\x00",
+		minterBalanceKey: "100",
+		"totalSupply":    "100",
+		"\x00allowance\x00recipient\x00minter\x00": "50",
+	})
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+	keys := worldState.Keys()
+
+	err := worldState.Submit(token.Mint(transactionContext, "10"))
+	require.EqualError(t, err, "the contract is paused")
+	err = worldState.Submit(token.Burn(transactionContext, "10"))
+	require.EqualError(t, err, "the contract is paused")
+	err = worldState.Submit(token.Transfer(transactionContext, recipientID, "10"))
+	require.EqualError(t, err, "the contract is paused")
+	err = worldState.Submit(token.Approve(transactionContext, recipientID, "10"))
+	require.EqualError(t, err, "the contract is paused")
+	err = worldState.Submit(token.TransferFrom(transactionContext, recipientID, minterID, "10"))
+	require.EqualError(t, err, "the contract is paused")
+	require.Equal(t, keys, worldState.Keys())
+	requireLastEvent(t, worldState, "", "")
+
+	// Queries are still served while the contract is paused
+	requireBalances(t, &token, worldState, map[string]string{minterID: "100", "totalSupply": "100"})
+}
+
+func TestFrozenAccountBlocksOperations(t *testing.T) {
+	token := chaincode.SmartContract{}
+	balances := map[string]string{
+		minterBalanceKey: "100",
+		"totalSupply":    "100",
+		"\x00allowance\x00minter\x00recipient\x00": "50",
+		"\x00frozen\x00recipient\x00":              "\x00",
+	}
+
+	// A frozen recipient cannot receive tokens nor be approved as a spender
+	worldState := prepWorldState(t, balances)
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	err := worldState.Submit(token.Transfer(transactionContext, recipientID, "10"))
+	require.EqualError(t, err, "account recipient is frozen")
+	err = worldState.Submit(token.Approve(transactionContext, recipientID, "10"))
+	require.EqualError(t, err, "account recipient is frozen")
+	err = worldState.Submit(token.TransferFrom(transactionContext, recipientID, minterID, "10"))
+	require.EqualError(t, err, "account recipient is frozen")
+	requireBalances(t, &token, worldState, map[string]string{minterID: "100", "totalSupply": "100"})
+
+	// A frozen spender cannot spend its allowance
+	err = worldState.Submit(token.TransferFrom(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), minterID, "other", "10"))
+	require.EqualError(t, err, "account recipient is frozen")
+
+	// A frozen minter can neither mint nor burn
+	worldState = prepWorldState(t, map[string]string{
+		minterBalanceKey:           "100",
+		"totalSupply":              "100",
+		"\x00frozen\x00minter\x00": "\x00",
+	})
+	transactionContext = mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	err = worldState.Submit(token.Mint(transactionContext, "10"))
+	require.EqualError(t, err, "account minter is frozen")
+	err = worldState.Submit(token.Burn(transactionContext, "10"))
+	require.EqualError(t, err, "account minter is frozen")
+}
@@ -0,0 +1,220 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define the roles of the role registry
+const (
+	// AdminRole grants and revokes roles
+	AdminRole = "ADMIN"
+	// MinterRole mints new tokens
+	MinterRole = "MINTER"
+	// BurnerRole burns tokens
+	BurnerRole = "BURNER"
+)
+
+// Define objectType names for prefix
+const rolePrefix = "role"
+
+// Define names of the role events
+const roleGrantedEventName = "RoleGranted"
+const roleRevokedEventName = "RoleRevoked"
+
+// RoleEvent is the payload of the RoleGranted and RoleRevoked events
+type RoleEvent struct {
+	Role      string `json:"role"`
+	Principal string `json:"principal"`
+	Sender    string `json:"sender"`
+	TxID      string `json:"txID"`
+}
+
+// GrantRole grants the role to the principal, an MSP ID or a client ID.
+// When granted to an MSP ID, every client of the MSP holds the role.
+// Only a client holding the ADMIN role can grant roles.
+// This function triggers a RoleGranted event
+func (s *SmartContract) GrantRole(ctx contractapi.TransactionContextInterface, role string, principal string) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
+
+	sender, err := checkRole(ctx, AdminRole)
+	if err != nil {
+		return err
+	}
+
+	granted, err := hasRole(ctx, role, principal)
+	if err != nil {
+		return err
+	}
+	if granted {
+		return fmt.Errorf("%s already has the role %s", principal, role)
+	}
+
+	err = grantRole(ctx, role, principal)
+	if err != nil {
+		return err
+	}
+
+	return setEvent(ctx, roleGrantedEventName, RoleEvent{role, principal, sender, ctx.GetStub().GetTxID()})
+}
+
+// RevokeRole revokes the role from the principal. The last holder of the ADMIN role cannot
+// be revoked, so that roles can always be administered.
+// Only a client holding the ADMIN role can revoke roles.
+// This function triggers a RoleRevoked event
+func (s *SmartContract) RevokeRole(ctx contractapi.TransactionContextInterface, role string, principal string) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
+
+	sender, err := checkRole(ctx, AdminRole)
+	if err != nil {
+		return err
+	}
+
+	granted, err := hasRole(ctx, role, principal)
+	if err != nil {
+		return err
+	}
+	if !granted {
+		return fmt.Errorf("%s does not have the role %s", principal, role)
+	}
+
+	if role == AdminRole {
+		admins, err := countRoleHolders(ctx, AdminRole)
+		if err != nil {
+			return err
+		}
+		if admins == 1 {
+			return fmt.Errorf("the role %s cannot be revoked from its last holder", AdminRole)
+		}
+	}
+
+	roleKey, err := roleKeyOf(ctx, role, principal)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(roleKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete role from world state: %v", err)
+	}
+
+	return setEvent(ctx, roleRevokedEventName, RoleEvent{role, principal, sender, ctx.GetStub().GetTxID()})
+}
+
+// HasRole returns true when the role has been granted to the principal itself.
+// A client ID also holds the roles granted to its MSP, which HasRole does not report.
+func (s *SmartContract) HasRole(ctx contractapi.TransactionContextInterface, role string, principal string) (bool, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return hasRole(ctx, role, principal)
+}
+
+// Helper Functions
+
+// roleKeyOf returns the world state key recording that the role is granted to the principal
+func roleKeyOf(ctx contractapi.TransactionContextInterface, role string, principal string) (string, error) {
+	switch role {
+	case AdminRole, MinterRole, BurnerRole:
+	default:
+		return "", fmt.Errorf("unknown role %s, expected one of %s, %s or %s", role, AdminRole, MinterRole, BurnerRole)
+	}
+	if principal == "" {
+		return "", fmt.Errorf("principal must not be empty")
+	}
+
+	roleKey, err := ctx.GetStub().CreateCompositeKey(rolePrefix, []string{role, principal})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", rolePrefix, err)
+	}
+
+	return roleKey, nil
+}
+
+// hasRole returns true when the role has been granted to the principal
+func hasRole(ctx contractapi.TransactionContextInterface, role string, principal string) (bool, error) {
+	roleKey, err := roleKeyOf(ctx, role, principal)
+	if err != nil {
+		return false, err
+	}
+	roleBytes, err := ctx.GetStub().GetState(roleKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read role from world state: %v", err)
+	}
+
+	return roleBytes != nil, nil
+}
+
+// grantRole records the role of the principal without any authorization check
+func grantRole(ctx contractapi.TransactionContextInterface, role string, principal string) error {
+	roleKey, err := roleKeyOf(ctx, role, principal)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(roleKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put role to world state: %v", err)
+	}
+
+	return nil
+}
+
+// checkRole returns the ID of the submitting client, or an error unless the role has been
+// granted to the client ID or to the MSP ID of the submitting client
+func checkRole(ctx contractapi.TransactionContextInterface, role string) (string, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	for _, principal := range []string{clientID, clientMSPID} {
+		granted, err := hasRole(ctx, role, principal)
+		if err != nil {
+			return "", err
+		}
+		if granted {
+			return clientID, nil
+		}
+	}
+
+	return "", fmt.Errorf("client is not authorized: the role %s is required", role)
+}
+
+// countRoleHolders returns the number of principals holding the role
+func countRoleHolders(ctx contractapi.TransactionContextInterface, role string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(rolePrefix, []string{role})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read holders of role %s: %v", role, err)
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		_, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, nil
+}
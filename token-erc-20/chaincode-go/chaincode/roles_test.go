@@ -0,0 +1,74 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantRole(t *testing.T) {
+	worldState := prepWorldState(t, nil)
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.GrantRole(transactionContext, chaincode.MinterRole, recipientID))
+	require.NoError(t, err)
+	require.Contains(t, worldState.Keys(), "\x00role\x00MINTER\x00recipient\x00")
+	requireLastEvent(t, worldState, "RoleGranted", `{"role":"MINTER","principal":"recipient","sender":"minter","txID":"tx1"}`)
+
+	hasRole, err := token.HasRole(transactionContext, chaincode.MinterRole, recipientID)
+	require.NoError(t, err)
+	require.True(t, hasRole)
+
+	err = worldState.Submit(token.GrantRole(transactionContext, chaincode.MinterRole, recipientID))
+	require.EqualError(t, err, "recipient already has the role MINTER")
+
+	err = worldState.Submit(token.GrantRole(transactionContext, "OWNER", recipientID))
+	require.EqualError(t, err, "unknown role OWNER, expected one of ADMIN, MINTER or BURNER")
+
+	err = worldState.Submit(token.GrantRole(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), chaincode.MinterRole, "Org2MSP"))
+	require.EqualError(t, err, "client is not authorized: the role ADMIN is required")
+}
+
+func TestRevokeRole(t *testing.T) {
+	worldState := prepWorldState(t, nil)
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.RevokeRole(transactionContext, chaincode.MinterRole, "Org1MSP"))
+	require.NoError(t, err)
+	require.NotContains(t, worldState.Keys(), "\x00role\x00MINTER\x00Org1MSP\x00")
+	requireLastEvent(t, worldState, "RoleRevoked", `{"role":"MINTER","principal":"Org1MSP","sender":"minter","txID":"tx1"}`)
+
+	err = worldState.Submit(token.RevokeRole(transactionContext, chaincode.BurnerRole, recipientID))
+	require.EqualError(t, err, "recipient does not have the role BURNER")
+
+	// Org1MSP is the only holder of the ADMIN role
+	err = worldState.Submit(token.RevokeRole(transactionContext, chaincode.AdminRole, "Org1MSP"))
+	require.EqualError(t, err, "the role ADMIN cannot be revoked from its last holder")
+	require.Contains(t, worldState.Keys(), "\x00role\x00ADMIN\x00Org1MSP\x00")
+
+	require.NoError(t, worldState.Submit(token.GrantRole(transactionContext, chaincode.AdminRole, recipientID)))
+	err = worldState.Submit(token.RevokeRole(transactionContext, chaincode.AdminRole, "Org1MSP"))
+	require.NoError(t, err)
+	require.NotContains(t, worldState.Keys(), "\x00role\x00ADMIN\x00Org1MSP\x00")
+}
+
+func TestMintRequiresRole(t *testing.T) {
+	worldState := prepWorldState(t, nil)
+	recipientContext := mocks.NewTransactionContext(worldState, recipientID, "Org2MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Mint(recipientContext, "100"))
+	require.EqualError(t, err, "client is not authorized: the role MINTER is required")
+	err = worldState.Submit(token.Burn(recipientContext, "100"))
+	require.EqualError(t, err, "client is not authorized: the role BURNER is required")
+
+	err = worldState.Submit(token.GrantRole(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), chaincode.MinterRole, recipientID))
+	require.NoError(t, err)
+
+	err = worldState.Submit(token.Mint(recipientContext, "100"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,238 @@
+package chaincode
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define key names for the snapshots
+const snapshotIDKey = "snapshotID"
+
+// Define objectType names for prefix
+const balanceSnapshotPrefix = "balanceSnapshot"
+const supplySnapshotPrefix = "supplySnapshot"
+
+// Define names of the snapshot events
+const snapshotEventName = "Snapshot"
+
+// SnapshotEvent is the payload of the Snapshot event
+type SnapshotEvent struct {
+	ID     uint64 `json:"id"`
+	Sender string `json:"sender"`
+	TxID   string `json:"txID"`
+}
+
+// Snapshot records the balances and the total supply as of the end of this transaction under
+// a new snapshot ID, which is returned. Snapshot IDs start at 1 and increase by 1.
+// Balances are not copied: the value of a balance at the snapshot is only recorded by the
+// first transaction changing it after the snapshot.
+// Only a client holding the ADMIN role can take snapshots.
+// This function triggers a Snapshot event
+func (s *SmartContract) Snapshot(ctx contractapi.TransactionContextInterface) (uint64, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sender, err := checkRole(ctx, AdminRole)
+	if err != nil {
+		return 0, err
+	}
+
+	snapshotID, err := currentSnapshotID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	snapshotID++
+
+	err = ctx.GetStub().PutState(snapshotIDKey, []byte(strconv.FormatUint(snapshotID, 10)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to put snapshot ID to world state: %v", err)
+	}
+
+	err = setEvent(ctx, snapshotEventName, SnapshotEvent{snapshotID, sender, ctx.GetStub().GetTxID()})
+	if err != nil {
+		return 0, err
+	}
+
+	return snapshotID, nil
+}
+
+// BalanceOfAt returns the balance of the account at the snapshot as a decimal string.
+// An account without balance at the snapshot has a balance of 0.
+func (s *SmartContract) BalanceOfAt(ctx contractapi.TransactionContextInterface, account string, snapshotID uint64) (string, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkSnapshotID(ctx, snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	balance, recorded, err := valueAt(ctx, balanceSnapshotPrefix, []string{account}, snapshotID)
+	if err != nil {
+		return "", err
+	}
+	if !recorded {
+		// The balance has not changed since the snapshot
+		balance, _, err = readBalance(ctx, account)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return balance.String(), nil
+}
+
+// TotalSupplyAt returns the total token supply at the snapshot as a decimal string
+func (s *SmartContract) TotalSupplyAt(ctx contractapi.TransactionContextInterface, snapshotID uint64) (string, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkSnapshotID(ctx, snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	totalSupply, recorded, err := valueAt(ctx, supplySnapshotPrefix, []string{}, snapshotID)
+	if err != nil {
+		return "", err
+	}
+	if !recorded {
+		// The total supply has not changed since the snapshot
+		totalSupply, _, err = readAmount(ctx, totalSupplyKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to retrieve total token supply: %v", err)
+		}
+	}
+
+	return totalSupply.String(), nil
+}
+
+// Helper Functions
+
+// currentSnapshotID returns the ID of the latest snapshot, or 0 if no snapshot was taken
+func currentSnapshotID(ctx contractapi.TransactionContextInterface) (uint64, error) {
+	snapshotIDBytes, err := ctx.GetStub().GetState(snapshotIDKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read snapshot ID from world state: %v", err)
+	}
+	if snapshotIDBytes == nil {
+		return 0, nil
+	}
+
+	snapshotID, err := strconv.ParseUint(string(snapshotIDBytes), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("corrupted snapshot ID: %v", err)
+	}
+
+	return snapshotID, nil
+}
+
+// checkSnapshotID returns an error unless the snapshot has been taken
+func checkSnapshotID(ctx contractapi.TransactionContextInterface, snapshotID uint64) error {
+	latestSnapshotID, err := currentSnapshotID(ctx)
+	if err != nil {
+		return err
+	}
+	if snapshotID == 0 || snapshotID > latestSnapshotID {
+		return fmt.Errorf("snapshot %d does not exist", snapshotID)
+	}
+
+	return nil
+}
+
+// formatSnapshotID pads the snapshot ID with zeros, so that the composite keys of a value
+// sort in the order of their snapshot IDs
+func formatSnapshotID(snapshotID uint64) string {
+	return fmt.Sprintf("%020d", snapshotID)
+}
+
+// recordSnapshot records the amount stored under the value key under the composite key of the
+// prefix, the attributes and the latest snapshot ID, unless it was already recorded for that
+// snapshot. It must be called before the value changes, so that the amount recorded is the
+// value at the snapshot.
+func recordSnapshot(ctx contractapi.TransactionContextInterface, prefix string, attributes []string, valueKey string) error {
+	snapshotID, err := currentSnapshotID(ctx)
+	if err != nil {
+		return err
+	}
+	if snapshotID == 0 {
+		return nil
+	}
+
+	snapshotKey, err := ctx.GetStub().CreateCompositeKey(prefix, append(attributes, formatSnapshotID(snapshotID)))
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", prefix, err)
+	}
+	snapshotBytes, err := ctx.GetStub().GetState(snapshotKey)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot from world state: %v", err)
+	}
+	if snapshotBytes != nil {
+		return nil
+	}
+
+	value, _, err := readAmount(ctx, valueKey)
+	if err != nil {
+		return err
+	}
+
+	return putAmount(ctx, snapshotKey, value)
+}
+
+// valueAt returns the value recorded for the snapshot under the prefix and attributes. A value is
+// recorded under the first snapshot after which it changed, so the value at the snapshot is
+// the first one recorded at or after it. When no value is recorded, the value has not changed
+// since the snapshot and recorded is false.
+func valueAt(ctx contractapi.TransactionContextInterface, prefix string, attributes []string, snapshotID uint64) (value *big.Int, recorded bool, err error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(prefix, attributes)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read snapshots: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, false, err
+		}
+
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(keyParts) != len(attributes)+1 {
+			return nil, false, fmt.Errorf("invalid snapshot key %s", queryResponse.Key)
+		}
+		recordedID, err := strconv.ParseUint(keyParts[len(attributes)], 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid snapshot key %s: %v", queryResponse.Key, err)
+		}
+		if recordedID < snapshotID {
+			continue
+		}
+
+		value, err = parseAmount(string(queryResponse.Value))
+		if err != nil {
+			return nil, false, fmt.Errorf("corrupted snapshot stored under %s: %v", queryResponse.Key, err)
+		}
+
+		return value, true, nil
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,62 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	_, err := token.BalanceOfAt(minterContext, minterID, 1)
+	require.EqualError(t, err, "snapshot 1 does not exist")
+
+	require.NoError(t, worldState.Submit(token.Transfer(minterContext, recipientID, "300")))
+	snapshotID, err := token.Snapshot(minterContext)
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, uint64(1), snapshotID)
+	requireLastEvent(t, worldState, "Snapshot", `{"id":1,"sender":"minter","txID":"tx1"}`)
+
+	require.NoError(t, worldState.Submit(token.Transfer(minterContext, recipientID, "100")))
+	require.NoError(t, worldState.Submit(token.Mint(minterContext, "500")))
+	snapshotID, err = token.Snapshot(minterContext)
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, uint64(2), snapshotID)
+	require.NoError(t, worldState.Submit(token.Burn(minterContext, "100")))
+
+	tests := []struct {
+		account    string
+		snapshotID uint64
+		want       string
+	}{
+		{minterID, 1, "700"},
+		{recipientID, 1, "300"},
+		{"other", 1, "0"},
+		{minterID, 2, "1100"},
+		{recipientID, 2, "400"},
+	}
+	for _, tt := range tests {
+		balance, err := token.BalanceOfAt(minterContext, tt.account, tt.snapshotID)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, balance, "balance of %s at snapshot %d", tt.account, tt.snapshotID)
+	}
+
+	totalSupply, err := token.TotalSupplyAt(minterContext, 1)
+	require.NoError(t, err)
+	require.Equal(t, "1000", totalSupply)
+	totalSupply, err = token.TotalSupplyAt(minterContext, 2)
+	require.NoError(t, err)
+	require.Equal(t, "1500", totalSupply)
+	requireBalances(t, token, worldState, map[string]string{minterID: "1000", recipientID: "400", "totalSupply": "1400"})
+
+	_, err = token.TotalSupplyAt(minterContext, 0)
+	require.EqualError(t, err, "snapshot 0 does not exist")
+	_, err = token.TotalSupplyAt(minterContext, 3)
+	require.EqualError(t, err, "snapshot 3 does not exist")
+
+	_, err = token.Snapshot(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"))
+	require.EqualError(t, worldState.Submit(err), "client is not authorized: the role ADMIN is required")
+}
@@ -1,10 +1,9 @@
 package chaincode
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"strconv"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -12,6 +11,9 @@ import (
 
 // Define key names for options
 const totalSupplyKey = "totalSupply"
+const nameKey = "name"
+const symbolKey = "symbol"
+const decimalsKey = "decimals"
 
 // Define objectType names for prefix
 const allowancePrefix = "allowance"
@@ -21,171 +23,150 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
-// event provides an organized struct for emitting events
-type event struct {
-	from  string
-	to    string
-	value int
-}
-
 // Mint creates new tokens and adds them to minter's account balance
+// amount is a decimal string, so that amounts of tokens with many decimals can be represented
 // This function triggers a Transfer event
-func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount int) error {
+func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount string) error {
 
-	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to mint new tokens
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get MSPID: %v", err)
-	}
-	if clientMSPID != "Org1MSP" {
-		return fmt.Errorf("client is not authorized to mint new tokens")
+		return err
 	}
 
-	// Get ID of submitting client identity
-	minter, err := ctx.GetClientIdentity().GetID()
+	// Check minter authorization - the MINTER role is granted to the central banker with privilege to mint new tokens
+	minter, err := checkRole(ctx, MinterRole)
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
-	if amount <= 0 {
-		return fmt.Errorf("mint amount must be a positive integer")
+	// Check that the contract is not paused and that no party is frozen
+	err = checkNotRestricted(ctx, minter)
+	if err != nil {
+		return err
 	}
 
-	currentBalanceBytes, err := ctx.GetStub().GetState(minter)
+	mintAmount, err := parsePositiveAmount(amount)
 	if err != nil {
-		return fmt.Errorf("failed to read minter account %s from world state: %v", minter, err)
+		return fmt.Errorf("invalid mint amount: %v", err)
 	}
 
-	var currentBalance int
-
 	// If minter current balance doesn't yet exist, we'll create it with a current balance of 0
-	if currentBalanceBytes == nil {
-		currentBalance = 0
-	} else {
-		currentBalance, _ = strconv.Atoi(string(currentBalanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
+	currentBalance, _, err := readBalance(ctx, minter)
+	if err != nil {
+		return fmt.Errorf("failed to read minter account %s: %v", minter, err)
 	}
 
-	updatedBalance := currentBalance + amount
-
-	err = ctx.GetStub().PutState(minter, []byte(strconv.Itoa(updatedBalance)))
+	updatedBalance, err := addAmounts(currentBalance, mintAmount)
 	if err != nil {
 		return err
 	}
 
-	// Update the totalSupply
-	totalSupplyBytes, err := ctx.GetStub().GetState(totalSupplyKey)
+	// If no tokens have been minted, initialize the totalSupply
+	totalSupply, _, err := readAmount(ctx, totalSupplyKey)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve total token supply: %v", err)
 	}
 
-	var totalSupply int
-
-	// If no tokens have been minted, initialize the totalSupply
-	if totalSupplyBytes == nil {
-		totalSupply = 0
-	} else {
-		totalSupply, _ = strconv.Atoi(string(totalSupplyBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
+	// Add the mint amount to the total supply
+	updatedTotalSupply, err := addAmounts(totalSupply, mintAmount)
+	if err != nil {
+		return err
 	}
 
-	// Add the mint amount to the total supply and update the state
-	totalSupply += amount
-	err = ctx.GetStub().PutState(totalSupplyKey, []byte(strconv.Itoa(totalSupply)))
+	err = putBalance(ctx, minter, updatedBalance)
 	if err != nil {
 		return err
 	}
-
-	// Emit the Transfer event
-	transferEvent := event{"0x0", minter, amount}
-	transferEventJSON, err := json.Marshal(transferEvent)
+	err = putTotalSupply(ctx, updatedTotalSupply)
 	if err != nil {
-		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		return err
 	}
-	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+
+	// Emit the Transfer event
+	err = emitTransfer(ctx, zeroAddress, minter, mintAmount)
 	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
+		return err
 	}
 
-	log.Printf("minter account %s balance updated from %d to %d", minter, currentBalance, updatedBalance)
+	log.Printf("minter account %s balance updated from %s to %s", minter, currentBalance, updatedBalance)
 
 	return nil
 }
 
 // Burn redeems tokens the minter's account balance
+// amount is a decimal string, so that amounts of tokens with many decimals can be represented
 // This function triggers a Transfer event
-func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, amount int) error {
+func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, amount string) error {
 
-	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to burn new tokens
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get MSPID: %v", err)
-	}
-	if clientMSPID != "Org1MSP" {
-		return fmt.Errorf("client is not authorized to mint new tokens")
+		return err
 	}
 
-	// Get ID of submitting client identity
-	minter, err := ctx.GetClientIdentity().GetID()
+	// Check burner authorization - the BURNER role is granted to the central banker with privilege to burn tokens
+	minter, err := checkRole(ctx, BurnerRole)
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
-	if amount <= 0 {
-		return errors.New("burn amount must be a positive integer")
+	// Check that the contract is not paused and that no party is frozen
+	err = checkNotRestricted(ctx, minter)
+	if err != nil {
+		return err
 	}
 
-	currentBalanceBytes, err := ctx.GetStub().GetState(minter)
+	burnAmount, err := parsePositiveAmount(amount)
 	if err != nil {
-		return fmt.Errorf("failed to read minter account %s from world state: %v", minter, err)
+		return fmt.Errorf("invalid burn amount: %v", err)
 	}
 
-	var currentBalance int
-
 	// Check if minter current balance exists
-	if currentBalanceBytes == nil {
-		return errors.New("The balance does not exist")
+	currentBalance, exists, err := readBalance(ctx, minter)
+	if err != nil {
+		return fmt.Errorf("failed to read minter account %s: %v", minter, err)
+	}
+	if !exists {
+		return fmt.Errorf("the balance does not exist")
 	}
 
-	currentBalance, _ = strconv.Atoi(string(currentBalanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
-
-	updatedBalance := currentBalance - amount
-
-	err = ctx.GetStub().PutState(minter, []byte(strconv.Itoa(updatedBalance)))
+	updatedBalance, err := subAmounts(currentBalance, burnAmount)
 	if err != nil {
-		return err
+		return fmt.Errorf("minter account %s has insufficient funds: %v", minter, err)
 	}
 
-	// Update the totalSupply
-	totalSupplyBytes, err := ctx.GetStub().GetState(totalSupplyKey)
+	// If no tokens have been minted, throw error
+	totalSupply, exists, err := readAmount(ctx, totalSupplyKey)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve total token supply: %v", err)
 	}
-
-	// If no tokens have been minted, throw error
-	if totalSupplyBytes == nil {
-		return errors.New("totalSupply does not exist")
+	if !exists {
+		return fmt.Errorf("totalSupply does not exist")
 	}
 
-	totalSupply, _ := strconv.Atoi(string(totalSupplyBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
-
-	// Subtract the burn amount to the total supply and update the state
-	totalSupply -= amount
-	err = ctx.GetStub().PutState(totalSupplyKey, []byte(strconv.Itoa(totalSupply)))
+	// Subtract the burn amount from the total supply
+	updatedTotalSupply, err := subAmounts(totalSupply, burnAmount)
 	if err != nil {
 		return err
 	}
 
-	// Emit the Transfer event
-	transferEvent := event{minter, "0x0", amount}
-	transferEventJSON, err := json.Marshal(transferEvent)
+	err = putBalance(ctx, minter, updatedBalance)
 	if err != nil {
-		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		return err
 	}
-	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	err = putTotalSupply(ctx, updatedTotalSupply)
 	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
+		return err
 	}
 
-	log.Printf("minter account %s balance updated from %d to %d", minter, currentBalance, updatedBalance)
+	// Emit the Transfer event
+	err = emitTransfer(ctx, minter, zeroAddress, burnAmount)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("minter account %s balance updated from %s to %s", minter, currentBalance, updatedBalance)
 
 	return nil
 }
@@ -193,7 +174,13 @@ func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, amount
 // Transfer transfers tokens from client account to recipient account
 // recipient account must be a valid clientID as returned by the ClientID() function
 // This function triggers a Transfer event
-func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, recipient string, amount int) error {
+func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, recipient string, amount string) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
 
 	// Get ID of submitting client identity
 	clientID, err := ctx.GetClientIdentity().GetID()
@@ -201,60 +188,76 @@ func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, re
 		return fmt.Errorf("failed to get client id: %v", err)
 	}
 
-	err = transferHelper(ctx, clientID, recipient, amount)
+	// Check that the contract is not paused and that no party is frozen
+	err = checkNotRestricted(ctx, clientID, recipient)
 	if err != nil {
-		return fmt.Errorf("failed to transfer: %v", err)
+		return err
 	}
 
-	// Emit the Transfer event
-	transferEvent := event{clientID, recipient, amount}
-	transferEventJSON, err := json.Marshal(transferEvent)
+	// transfer of 0 is allowed in ERC-20
+	transferAmount, err := parseAmount(amount)
+	if err != nil {
+		return fmt.Errorf("invalid transfer amount: %v", err)
+	}
+
+	err = transferHelper(ctx, clientID, recipient, transferAmount)
 	if err != nil {
-		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		return fmt.Errorf("failed to transfer: %v", err)
 	}
-	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+
+	// Emit the Transfer event
+	err = emitTransfer(ctx, clientID, recipient, transferAmount)
 	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
+		return err
 	}
 
 	return nil
 }
 
-// BalanceOf returns the balance of the given account
-func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string) (int, error) {
-	balanceBytes, err := ctx.GetStub().GetState(account)
+// BalanceOf returns the balance of the given account as a decimal string
+func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if balanceBytes == nil {
-		return 0, fmt.Errorf("the account %s does not exist", account)
+		return "", err
 	}
 
-	balance, _ := strconv.Atoi(string(balanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
+	balance, exists, err := readBalance(ctx, account)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("the account %s does not exist", account)
+	}
 
-	return balance, nil
+	return balance.String(), nil
 }
 
-// ClientAccountBalance returns the balance of the requesting client's account
-func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextInterface) (int, error) {
+// ClientAccountBalance returns the balance of the requesting client's account as a decimal string
+func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextInterface) (string, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return "", err
+	}
 
 	// Get ID of submitting client identity
 	clientID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get client id: %v", err)
+		return "", fmt.Errorf("failed to get client id: %v", err)
 	}
 
-	balanceBytes, err := ctx.GetStub().GetState(clientID)
+	balance, exists, err := readBalance(ctx, clientID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read from world state: %v", err)
+		return "", err
 	}
-	if balanceBytes == nil {
-		return 0, fmt.Errorf("the account %s does not exist", clientID)
+	if !exists {
+		return "", fmt.Errorf("the account %s does not exist", clientID)
 	}
 
-	balance, _ := strconv.Atoi(string(balanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
-
-	return balance, nil
+	return balance.String(), nil
 }
 
 // ClientAccountID returns the id of the requesting client's account
@@ -262,6 +265,12 @@ func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextI
 // Users can use this function to get their own account id, which they can then give to others as the payment address
 func (s *SmartContract) ClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
 
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return "", err
+	}
+
 	// Get ID of submitting client identity
 	clientAccountID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
@@ -271,100 +280,96 @@ func (s *SmartContract) ClientAccountID(ctx contractapi.TransactionContextInterf
 	return clientAccountID, nil
 }
 
-// TotalSupply returns the total token supply
-func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (int, error) {
+// TotalSupply returns the total token supply as a decimal string
+func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (string, error) {
 
-	// Retrieve total supply of tokens from state of smart contract
-	totalSupplyBytes, err := ctx.GetStub().GetState(totalSupplyKey)
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to retrieve total token supply: %v", err)
+		return "", err
 	}
 
-	var totalSupply int
-
+	// Retrieve total supply of tokens from state of smart contract
 	// If no tokens have been minted, return 0
-	if totalSupplyBytes == nil {
-		totalSupply = 0
-	} else {
-		totalSupply, _ = strconv.Atoi(string(totalSupplyBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
+	totalSupply, _, err := readAmount(ctx, totalSupplyKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve total token supply: %v", err)
 	}
 
-	log.Printf("TotalSupply: %d tokens", totalSupply)
+	log.Printf("TotalSupply: %s tokens", totalSupply)
 
-	return totalSupply, nil
+	return totalSupply.String(), nil
 }
 
 // Approve allows the spender to withdraw from the calling client's token account
 // The spender can withdraw multiple times if necessary, up to the value amount
 // This function triggers an Approval event
-func (s *SmartContract) Approve(ctx contractapi.TransactionContextInterface, spender string, value int) error {
+func (s *SmartContract) Approve(ctx contractapi.TransactionContextInterface, spender string, value string) error {
 
-	// Get ID of submitting client identity
-	owner, err := ctx.GetClientIdentity().GetID()
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
-	// Create allowanceKey
-	allowanceKey, err := ctx.GetStub().CreateCompositeKey(allowancePrefix, []string{owner, spender})
+	// Get ID of submitting client identity
+	owner, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return fmt.Errorf("failed to create the composite key for prefix %s: %v", allowancePrefix, err)
+		return fmt.Errorf("failed to get client id: %v", err)
 	}
 
-	// Update the state of the smart contract by adding the allowanceKey and value
-	err = ctx.GetStub().PutState(allowanceKey, []byte(strconv.Itoa(value)))
+	// Check that the contract is not paused and that no party is frozen
+	err = checkNotRestricted(ctx, owner, spender)
 	if err != nil {
-		return fmt.Errorf("failed to update state of smart contract for key %s: %v", allowanceKey, err)
+		return err
 	}
 
-	// Emit the Approval event
-	approvalEvent := event{owner, spender, value}
-	approvalEventJSON, err := json.Marshal(approvalEvent)
+	allowance, err := parseAmount(value)
 	if err != nil {
-		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		return fmt.Errorf("invalid allowance: %v", err)
 	}
-	err = ctx.GetStub().SetEvent("Approval", approvalEventJSON)
-	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
-	}
-
-	log.Printf("client %s approved a withdrawal allowance of %d for spender %s", owner, value, spender)
 
-	return nil
+	// Update the state of the smart contract with an allowance that never expires
+	return setAllowance(ctx, owner, spender, allowance, 0)
 }
 
-// Allowance returns the amount still available for the spender to withdraw from the owner
-func (s *SmartContract) Allowance(ctx contractapi.TransactionContextInterface, owner string, spender string) (int, error) {
+// Allowance returns the amount still available for the spender to withdraw from the owner, as a decimal string
+func (s *SmartContract) Allowance(ctx contractapi.TransactionContextInterface, owner string, spender string) (string, error) {
 
-	// Create allowanceKey
-	allowanceKey, err := ctx.GetStub().CreateCompositeKey(allowancePrefix, []string{owner, spender})
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", allowancePrefix, err)
+		return "", err
 	}
 
 	// Read the allowance amount from the world state
-	allowanceBytes, err := ctx.GetStub().GetState(allowanceKey)
+	// If no current allowance, or if it has expired, return 0
+	allowance, expiresAt, err := readAllowance(ctx, owner, spender)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read allowance for %s from world state: %v", allowanceKey, err)
+		return "", fmt.Errorf("failed to read allowance: %v", err)
 	}
-
-	var allowance int
-
-	// If no current allowance, set allowance to 0
-	if allowanceBytes == nil {
-		allowance = 0
-	} else {
-		allowance, err = strconv.Atoi(string(allowanceBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
+	expired, err := isExpired(ctx, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	if expired {
+		allowance = new(big.Int)
 	}
 
-	log.Printf("The allowance left for spender %s to withdraw from owner %s: %d", spender, owner, allowance)
+	log.Printf("The allowance left for spender %s to withdraw from owner %s: %s", spender, owner, allowance)
 
-	return allowance, nil
+	return allowance.String(), nil
 }
 
 // TransferFrom transfers the value amount from the "from" address to the "to" address
 // This function triggers a Transfer event
-func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
+func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value string) error {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return err
+	}
 
 	// Get ID of submitting client identity
 	spender, err := ctx.GetClientIdentity().GetID()
@@ -372,108 +377,249 @@ func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface
 		return fmt.Errorf("failed to get client id: %v", err)
 	}
 
-	// Create allowanceKey
-	allowanceKey, err := ctx.GetStub().CreateCompositeKey(allowancePrefix, []string{from, spender})
+	// Check that the contract is not paused and that no party is frozen
+	err = checkNotRestricted(ctx, spender, from, to)
 	if err != nil {
-		return fmt.Errorf("failed to create the composite key for prefix %s: %v", allowancePrefix, err)
+		return err
+	}
+
+	transferAmount, err := parseAmount(value)
+	if err != nil {
+		return fmt.Errorf("invalid transfer amount: %v", err)
 	}
 
 	// Retrieve the allowance of the spender
-	currentAllowanceBytes, err := ctx.GetStub().GetState(allowanceKey)
+	currentAllowance, expiresAt, err := readAllowance(ctx, from, spender)
 	if err != nil {
-		return fmt.Errorf("failed to retrieve the allowance for %s from world state: %v", allowanceKey, err)
+		return fmt.Errorf("failed to retrieve the allowance: %v", err)
 	}
 
-	var currentAllowance int
-	currentAllowance, _ = strconv.Atoi(string(currentAllowanceBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
+	// Check that the allowance has not expired
+	expired, err := isExpired(ctx, expiresAt)
+	if err != nil {
+		return err
+	}
+	if expired {
+		return fmt.Errorf("the allowance of spender %s has expired", spender)
+	}
 
 	// Check if transferred value is less than allowance
-	if currentAllowance < value {
+	updatedAllowance, err := subAmounts(currentAllowance, transferAmount)
+	if err != nil {
 		return fmt.Errorf("spender does not have enough allowance for transfer")
 	}
 
 	// Initiate the transfer
-	err = transferHelper(ctx, from, to, value)
+	err = transferHelper(ctx, from, to, transferAmount)
 	if err != nil {
 		return fmt.Errorf("failed to transfer: %v", err)
 	}
 
-	// Decrease the allowance
-	updatedAllowance := currentAllowance - value
-	err = ctx.GetStub().PutState(allowanceKey, []byte(strconv.Itoa(updatedAllowance)))
+	// Decrease the allowance, keeping its expiry
+	allowanceKey, err := allowanceKeyOf(ctx, from, spender)
+	if err != nil {
+		return err
+	}
+	err = putAllowance(ctx, allowanceKey, updatedAllowance, expiresAt)
 	if err != nil {
 		return err
 	}
 
 	// Emit the Transfer event
-	transferEvent := event{from, to, value}
-	transferEventJSON, err := json.Marshal(transferEvent)
+	err = emitTransfer(ctx, from, to, transferAmount)
 	if err != nil {
-		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		return err
 	}
-	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+
+	log.Printf("spender %s allowance updated from %s to %s", spender, currentAllowance, updatedAllowance)
+
+	return nil
+}
+
+// Name returns a descriptive name for fungible tokens in this contract
+// returns {String} Returns the name of the token
+func (s *SmartContract) Name(ctx contractapi.TransactionContextInterface) (string, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
+		return "", err
 	}
 
-	log.Printf("spender %s allowance updated from %d to %d", spender, currentAllowance, updatedAllowance)
+	bytes, err := ctx.GetStub().GetState(nameKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Name bytes: %v", err)
+	}
 
-	return nil
+	return string(bytes), nil
 }
 
-// Helper Functions
+// Symbol returns an abbreviated name for fungible tokens in this contract.
+// returns {String} Returns the symbol of the token
+func (s *SmartContract) Symbol(ctx contractapi.TransactionContextInterface) (string, error) {
 
-// transferHelper is a helper function that transfers tokens from the "from" address to the "to" address
-// Dependant functions include Transfer and TransferFrom
-func transferHelper(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
+	if err != nil {
+		return "", err
+	}
 
-	if value < 0 { // transfer of 0 is allowed in ERC-20, so just validate against negative amounts
-		return fmt.Errorf("transfer amount cannot be negative")
+	bytes, err := ctx.GetStub().GetState(symbolKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Symbol: %v", err)
 	}
 
-	fromCurrentBalanceBytes, err := ctx.GetStub().GetState(from)
+	return string(bytes), nil
+}
+
+// Decimals returns the number of decimals the token uses
+// e.g. 8, means to divide the token amount by 100000000 to get its user representation
+// returns {Number} Returns the number of decimals
+func (s *SmartContract) Decimals(ctx contractapi.TransactionContextInterface) (uint8, error) {
+
+	// Check if contract has been initialized first
+	err := checkInitialized(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read client account %s from world state: %v", from, err)
+		return 0, err
 	}
 
-	if fromCurrentBalanceBytes == nil {
-		return fmt.Errorf("client account %s has no balance", from)
+	bytes, err := ctx.GetStub().GetState(decimalsKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get Decimals: %v", err)
 	}
 
-	fromCurrentBalance, _ := strconv.Atoi(string(fromCurrentBalanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
+	decimals, err := strconv.ParseUint(string(bytes), 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse Decimals %s: %v", bytes, err)
+	}
 
-	if fromCurrentBalance < value {
-		return fmt.Errorf("client account %s has insufficient funds", from)
+	return uint8(decimals), nil
+}
+
+// Initialize sets the information for a token and initializes the contract.
+// It can only be called once, by any client. The admin, an MSP ID or a client ID, is granted
+// the ADMIN role and must be the MSP ID or the client ID of the submitting client.
+// param {String} name The name of the token
+// param {String} symbol The symbol of the token
+// param {Number} decimals The decimals of the token
+// param {String} admin The initial holder of the ADMIN role
+// This function triggers a RoleGranted event
+func (s *SmartContract) Initialize(ctx contractapi.TransactionContextInterface, name string, symbol string, decimals uint8, admin string) (bool, error) {
+
+	// Check the client can only appoint itself or its organization as the initial admin
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return false, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return false, fmt.Errorf("failed to get client id: %v", err)
+	}
+	if admin != clientID && admin != clientMSPID {
+		return false, fmt.Errorf("the admin must be the client ID or the MSP ID of the submitting client")
 	}
 
-	toCurrentBalanceBytes, err := ctx.GetStub().GetState(to)
+	// Check contract options are not already set, client is not authorized to change them once initialized
+	bytes, err := ctx.GetStub().GetState(nameKey)
 	if err != nil {
-		return fmt.Errorf("failed to read recipient account %s from world state: %v", to, err)
+		return false, fmt.Errorf("failed to get Name: %v", err)
+	}
+	if bytes != nil {
+		return false, fmt.Errorf("contract options are already set, client is not authorized to change them")
 	}
 
-	var toCurrentBalance int
-	// If recipient current balance doesn't yet exist, we'll create it with a current balance of 0
-	if toCurrentBalanceBytes == nil {
-		toCurrentBalance = 0
-	} else {
-		toCurrentBalance, _ = strconv.Atoi(string(toCurrentBalanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
+	if name == "" || symbol == "" {
+		return false, fmt.Errorf("token name and symbol must not be empty")
+	}
+
+	err = ctx.GetStub().PutState(nameKey, []byte(name))
+	if err != nil {
+		return false, fmt.Errorf("failed to set token name: %v", err)
 	}
 
-	fromUpdatedBalance := fromCurrentBalance - value
-	toUpdatedBalance := toCurrentBalance + value
+	err = ctx.GetStub().PutState(symbolKey, []byte(symbol))
+	if err != nil {
+		return false, fmt.Errorf("failed to set symbol: %v", err)
+	}
 
-	err = ctx.GetStub().PutState(from, []byte(strconv.Itoa(fromUpdatedBalance)))
+	err = ctx.GetStub().PutState(decimalsKey, []byte(strconv.Itoa(int(decimals))))
 	if err != nil {
-		return err
+		return false, fmt.Errorf("failed to set decimals: %v", err)
+	}
+
+	err = grantRole(ctx, AdminRole, admin)
+	if err != nil {
+		return false, err
+	}
+
+	err = setEvent(ctx, roleGrantedEventName, RoleEvent{AdminRole, admin, clientID, ctx.GetStub().GetTxID()})
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("name: %s, symbol: %s, decimals: %d, admin: %s", name, symbol, decimals, admin)
+
+	return true, nil
+}
+
+// Helper Functions
+
+// checkInitialized returns an error until the contract options have been set with Initialize
+func checkInitialized(ctx contractapi.TransactionContextInterface) error {
+	tokenName, err := ctx.GetStub().GetState(nameKey)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if tokenName == nil {
+		return fmt.Errorf("contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	return nil
+}
+
+// transferHelper is a helper function that transfers tokens from the "from" address to the "to" address
+// Dependant functions include Transfer and TransferFrom
+func transferHelper(ctx contractapi.TransactionContextInterface, from string, to string, value *big.Int) error {
+
+	fromCurrentBalance, exists, err := readBalance(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to read client account %s: %v", from, err)
+	}
+	if !exists {
+		return fmt.Errorf("client account %s has no balance", from)
+	}
+
+	fromUpdatedBalance, err := subAmounts(fromCurrentBalance, value)
+	if err != nil {
+		return fmt.Errorf("client account %s has insufficient funds", from)
+	}
+
+	// If recipient current balance doesn't yet exist, we'll create it with a current balance of 0
+	toCurrentBalance, _, err := readBalance(ctx, to)
+	if err != nil {
+		return fmt.Errorf("failed to read recipient account %s: %v", to, err)
+	}
+
+	// A transfer to self leaves the balance unchanged
+	toUpdatedBalance := toCurrentBalance
+	if to != from {
+		toUpdatedBalance, err = addAmounts(toCurrentBalance, value)
+		if err != nil {
+			return err
+		}
+		err = putBalance(ctx, from, fromUpdatedBalance)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = ctx.GetStub().PutState(to, []byte(strconv.Itoa(toUpdatedBalance)))
+	err = putBalance(ctx, to, toUpdatedBalance)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("client %s balance updated from %d to %d", from, fromCurrentBalance, fromUpdatedBalance)
-	log.Printf("recipient %s balance updated from %d to %d", to, toCurrentBalance, toUpdatedBalance)
+	log.Printf("client %s balance updated from %s to %s", from, fromCurrentBalance, fromUpdatedBalance)
+	log.Printf("recipient %s balance updated from %s to %s", to, toCurrentBalance, toUpdatedBalance)
 
 	return nil
 }
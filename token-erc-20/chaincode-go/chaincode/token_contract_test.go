@@ -0,0 +1,506 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	minterID    = "minter"
+	recipientID = "recipient"
+	txID        = "tx1"
+
+	minterBalanceKey    = "\x00balance\x00minter\x00"
+	recipientBalanceKey = "\x00balance\x00recipient\x00"
+)
+
+// prepWorldState returns an initialized world state holding the given keys and values,
+// in which Org1MSP holds the ADMIN, MINTER and BURNER roles
+func prepWorldState(t *testing.T, state map[string]string) *mocks.WorldState {
+	worldState := mocks.NewWorldState(txID, time.Unix(1600000000, 0))
+	seed := map[string]string{
+		"name":                              "some name",
+		"\x00role\x00ADMIN\x00Org1MSP\x00":  "\x00",
+		"\x00role\x00MINTER\x00Org1MSP\x00": "\x00",
+		"\x00role\x00BURNER\x00Org1MSP\x00": "\x00",
+	}
+	for key, value := range state {
+		seed[key] = value
+	}
+	for key, value := range seed {
+		require.NoError(t, worldState.PutState(key, []byte(value)))
+	}
+	worldState.Commit()
+
+	return worldState
+}
+
+// requireLastEvent checks the name and exact JSON payload of the event of the last committed transaction
+func requireLastEvent(t *testing.T, worldState *mocks.WorldState, name string, payload string) {
+	eventName, eventPayload := worldState.Event()
+	require.Equal(t, name, eventName)
+	require.Equal(t, payload, string(eventPayload))
+}
+
+func TestInitialize(t *testing.T) {
+	worldState := mocks.NewWorldState(txID, time.Unix(1600000000, 0))
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Mint(minterContext, "100"))
+	require.EqualError(t, err, "contract options need to be set before calling any function, call Initialize() to initialize contract")
+
+	ok, err := token.Initialize(minterContext, "some name", "SYM", 2, "Org1MSP")
+	require.NoError(t, worldState.Submit(err))
+	require.True(t, ok)
+	require.Equal(t, []string{"\x00role\x00ADMIN\x00Org1MSP\x00", "decimals", "name", "symbol"}, worldState.Keys())
+	requireLastEvent(t, worldState, "RoleGranted", `{"role":"ADMIN","principal":"Org1MSP","sender":"minter","txID":"tx1"}`)
+	decimals, err := token.Decimals(minterContext)
+	require.NoError(t, err)
+	require.Equal(t, uint8(2), decimals)
+
+	_, err = token.Initialize(minterContext, "other name", "OTH", 2, "Org1MSP")
+	require.EqualError(t, worldState.Submit(err), "contract options are already set, client is not authorized to change them")
+
+	worldState = mocks.NewWorldState(txID, time.Unix(1600000000, 0))
+	_, err = token.Initialize(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), "other name", "OTH", 2, recipientID)
+	require.EqualError(t, worldState.Submit(err), "the admin must be the client ID or the MSP ID of the submitting client")
+	require.Empty(t, worldState.Keys())
+
+	// any organization can initialize the contract and appoint itself as the admin
+	_, err = token.Initialize(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), "other name", "OTH", 2, recipientID)
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, []string{"\x00role\x00ADMIN\x00recipient\x00", "decimals", "name", "symbol"}, worldState.Keys())
+}
+
+func TestMintEvent(t *testing.T) {
+	worldState := prepWorldState(t, nil)
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Mint(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), "100"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Transfer", `{"from":"0x0","to":"minter","value":"100","txID":"tx1"}`)
+}
+
+func TestBurnEvent(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{minterBalanceKey: "100", "totalSupply": "100"})
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Burn(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), "40"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Transfer", `{"from":"minter","to":"0x0","value":"40","txID":"tx1"}`)
+}
+
+func TestTransferEvent(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{minterBalanceKey: "100"})
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Transfer(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), recipientID, "30"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Transfer", `{"from":"minter","to":"recipient","value":"30","txID":"tx1"}`)
+}
+
+func TestApproveEvent(t *testing.T) {
+	worldState := prepWorldState(t, nil)
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.Approve(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), recipientID, "50"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Approval", `{"owner":"minter","spender":"recipient","value":"50","txID":"tx1"}`)
+}
+
+func TestTransferFromEvent(t *testing.T) {
+	worldState := prepWorldState(t, map[string]string{
+		minterBalanceKey: "100",
+		"\x00allowance\x00minter\x00recipient\x00": "50",
+	})
+	token := chaincode.SmartContract{}
+
+	err := worldState.Submit(token.TransferFrom(mocks.NewTransactionContext(worldState, recipientID, "Org2MSP"), minterID, "other", "20"))
+	require.NoError(t, err)
+	requireLastEvent(t, worldState, "Transfer", `{"from":"minter","to":"other","value":"20","txID":"tx1"}`)
+}
+
+// newToken returns an initialized contract over an in-memory world state, in which Org1MSP
+// holds the ADMIN, MINTER and BURNER roles and the minter account holds 1000 tokens
+func newToken(t *testing.T) (*chaincode.SmartContract, *mocks.WorldState) {
+	worldState := mocks.NewWorldState(txID, time.Unix(1600000000, 0))
+	token := &chaincode.SmartContract{}
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	_, err := token.Initialize(minterContext, "some name", "SYM", 2, "Org1MSP")
+	require.NoError(t, worldState.Submit(err))
+	require.NoError(t, worldState.Submit(token.GrantRole(minterContext, chaincode.MinterRole, "Org1MSP")))
+	require.NoError(t, worldState.Submit(token.GrantRole(minterContext, chaincode.BurnerRole, "Org1MSP")))
+	require.NoError(t, worldState.Submit(token.Mint(minterContext, "1000")))
+
+	return token, worldState
+}
+
+// requireBalances checks the balance of each account, and the total supply under the key totalSupply
+func requireBalances(t *testing.T, token *chaincode.SmartContract, worldState *mocks.WorldState, balances map[string]string) {
+	transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	for account, expected := range balances {
+		var balance string
+		var err error
+		if account == "totalSupply" {
+			balance, err = token.TotalSupply(transactionContext)
+		} else {
+			balance, err = token.BalanceOf(transactionContext, account)
+		}
+		require.NoError(t, err)
+		require.Equal(t, expected, balance, account)
+	}
+}
+
+func TestMint(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientID     string
+		mspID        string
+		amount       string
+		wantErr      string
+		wantBalances map[string]string
+	}{
+		{
+			name:         "minter role granted to MSP",
+			clientID:     minterID,
+			mspID:        "Org1MSP",
+			amount:       "500",
+			wantBalances: map[string]string{minterID: "1500", "totalSupply": "1500"},
+		},
+		{
+			name:         "new account of minter MSP",
+			clientID:     recipientID,
+			mspID:        "Org1MSP",
+			amount:       "1",
+			wantBalances: map[string]string{minterID: "1000", recipientID: "1", "totalSupply": "1001"},
+		},
+		{
+			name:         "client without minter role",
+			clientID:     recipientID,
+			mspID:        "Org2MSP",
+			amount:       "500",
+			wantErr:      "client is not authorized: the role MINTER is required",
+			wantBalances: map[string]string{minterID: "1000", "totalSupply": "1000"},
+		},
+		{
+			name:         "zero amount",
+			clientID:     minterID,
+			mspID:        "Org1MSP",
+			amount:       "0",
+			wantErr:      "invalid mint amount: amount must be a positive integer",
+			wantBalances: map[string]string{minterID: "1000", "totalSupply": "1000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+
+			err := worldState.Submit(token.Mint(mocks.NewTransactionContext(worldState, tt.clientID, tt.mspID), tt.amount))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			requireBalances(t, token, worldState, tt.wantBalances)
+		})
+	}
+}
+
+func TestBurn(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientID     string
+		mspID        string
+		amount       string
+		wantErr      string
+		wantBalances map[string]string
+	}{
+		{
+			name:         "burner role granted to MSP",
+			clientID:     minterID,
+			mspID:        "Org1MSP",
+			amount:       "400",
+			wantBalances: map[string]string{minterID: "600", "totalSupply": "600"},
+		},
+		{
+			name:         "whole balance",
+			clientID:     minterID,
+			mspID:        "Org1MSP",
+			amount:       "1000",
+			wantBalances: map[string]string{minterID: "0", "totalSupply": "0"},
+		},
+		{
+			name:         "client without burner role",
+			clientID:     recipientID,
+			mspID:        "Org2MSP",
+			amount:       "400",
+			wantErr:      "client is not authorized: the role BURNER is required",
+			wantBalances: map[string]string{minterID: "1000", "totalSupply": "1000"},
+		},
+		{
+			name:         "insufficient balance",
+			clientID:     minterID,
+			mspID:        "Org1MSP",
+			amount:       "1001",
+			wantErr:      "minter account minter has insufficient funds: arithmetic underflow: 1000 - 1001 is negative",
+			wantBalances: map[string]string{minterID: "1000", "totalSupply": "1000"},
+		},
+		{
+			name:         "account without balance",
+			clientID:     recipientID,
+			mspID:        "Org1MSP",
+			amount:       "1",
+			wantErr:      "the balance does not exist",
+			wantBalances: map[string]string{minterID: "1000", "totalSupply": "1000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+
+			err := worldState.Submit(token.Burn(mocks.NewTransactionContext(worldState, tt.clientID, tt.mspID), tt.amount))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			requireBalances(t, token, worldState, tt.wantBalances)
+		})
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientID     string
+		recipient    string
+		amount       string
+		wantErr      string
+		wantBalances map[string]string
+	}{
+		{
+			name:         "part of balance",
+			clientID:     minterID,
+			recipient:    recipientID,
+			amount:       "300",
+			wantBalances: map[string]string{minterID: "700", recipientID: "300", "totalSupply": "1000"},
+		},
+		{
+			name:         "whole balance",
+			clientID:     minterID,
+			recipient:    recipientID,
+			amount:       "1000",
+			wantBalances: map[string]string{minterID: "0", recipientID: "1000"},
+		},
+		{
+			name:         "zero amount",
+			clientID:     minterID,
+			recipient:    recipientID,
+			amount:       "0",
+			wantBalances: map[string]string{minterID: "1000", recipientID: "0"},
+		},
+		{
+			name:         "insufficient balance",
+			clientID:     minterID,
+			recipient:    recipientID,
+			amount:       "1001",
+			wantErr:      "failed to transfer: client account minter has insufficient funds",
+			wantBalances: map[string]string{minterID: "1000"},
+		},
+		{
+			name:         "sender without balance",
+			clientID:     recipientID,
+			recipient:    minterID,
+			amount:       "1",
+			wantErr:      "failed to transfer: client account recipient has no balance",
+			wantBalances: map[string]string{minterID: "1000"},
+		},
+		{
+			name:         "self-transfer",
+			clientID:     minterID,
+			recipient:    minterID,
+			amount:       "400",
+			wantBalances: map[string]string{minterID: "1000", "totalSupply": "1000"},
+		},
+		{
+			name:         "self-transfer exceeding balance",
+			clientID:     minterID,
+			recipient:    minterID,
+			amount:       "1001",
+			wantErr:      "failed to transfer: client account minter has insufficient funds",
+			wantBalances: map[string]string{minterID: "1000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+
+			err := worldState.Submit(token.Transfer(mocks.NewTransactionContext(worldState, tt.clientID, "Org1MSP"), tt.recipient, tt.amount))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			requireBalances(t, token, worldState, tt.wantBalances)
+		})
+	}
+}
+
+func TestApproveAndAllowance(t *testing.T) {
+	tests := []struct {
+		name          string
+		values        []string
+		wantErr       string
+		wantAllowance string
+	}{
+		{
+			name:          "no approval",
+			wantAllowance: "0",
+		},
+		{
+			name:          "approval",
+			values:        []string{"200"},
+			wantAllowance: "200",
+		},
+		{
+			name:          "approval exceeding balance",
+			values:        []string{"5000"},
+			wantAllowance: "5000",
+		},
+		{
+			name:          "approval replaces previous one",
+			values:        []string{"200", "50"},
+			wantAllowance: "50",
+		},
+		{
+			name:          "revocation",
+			values:        []string{"200", "0"},
+			wantAllowance: "0",
+		},
+		{
+			name:          "invalid value",
+			values:        []string{"200", "-1"},
+			wantErr:       "invalid allowance: invalid amount -1: amount must be a non-negative decimal integer",
+			wantAllowance: "200",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+			transactionContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+			var err error
+			for _, value := range tt.values {
+				err = worldState.Submit(token.Approve(transactionContext, recipientID, value))
+			}
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			allowance, err := token.Allowance(transactionContext, minterID, recipientID)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAllowance, allowance)
+			allowance, err = token.Allowance(transactionContext, recipientID, minterID)
+			require.NoError(t, err)
+			require.Equal(t, "0", allowance)
+		})
+	}
+}
+
+func TestTransferFrom(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowance     string
+		spender       string
+		values        []string
+		wantErr       string
+		wantAllowance string
+		wantBalances  map[string]string
+	}{
+		{
+			name:          "part of allowance",
+			allowance:     "300",
+			spender:       recipientID,
+			values:        []string{"100"},
+			wantAllowance: "200",
+			wantBalances:  map[string]string{minterID: "900", "other": "100"},
+		},
+		{
+			name:          "allowance exhausted exactly",
+			allowance:     "300",
+			spender:       recipientID,
+			values:        []string{"100", "200"},
+			wantAllowance: "0",
+			wantBalances:  map[string]string{minterID: "700", "other": "300"},
+		},
+		{
+			name:          "transfer after allowance exhaustion",
+			allowance:     "300",
+			spender:       recipientID,
+			values:        []string{"300", "1"},
+			wantErr:       "spender does not have enough allowance for transfer",
+			wantAllowance: "0",
+			wantBalances:  map[string]string{minterID: "700", "other": "300"},
+		},
+		{
+			name:          "value exceeding allowance",
+			allowance:     "300",
+			spender:       recipientID,
+			values:        []string{"301"},
+			wantErr:       "spender does not have enough allowance for transfer",
+			wantAllowance: "300",
+			wantBalances:  map[string]string{minterID: "1000"},
+		},
+		{
+			name:          "spender without allowance",
+			allowance:     "300",
+			spender:       "other",
+			values:        []string{"1"},
+			wantErr:       "spender does not have enough allowance for transfer",
+			wantAllowance: "300",
+			wantBalances:  map[string]string{minterID: "1000"},
+		},
+		{
+			name:          "allowance exceeding balance",
+			allowance:     "2000",
+			spender:       recipientID,
+			values:        []string{"1500"},
+			wantErr:       "failed to transfer: client account minter has insufficient funds",
+			wantAllowance: "2000",
+			wantBalances:  map[string]string{minterID: "1000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+			require.NoError(t, worldState.Submit(token.Approve(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), recipientID, tt.allowance)))
+			spenderContext := mocks.NewTransactionContext(worldState, tt.spender, "Org2MSP")
+
+			var err error
+			for i, value := range tt.values {
+				err = worldState.Submit(token.TransferFrom(spenderContext, minterID, "other", value))
+				if i < len(tt.values)-1 {
+					require.NoError(t, err)
+				}
+			}
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			allowance, err := token.Allowance(spenderContext, minterID, recipientID)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAllowance, allowance)
+			requireBalances(t, token, worldState, tt.wantBalances)
+		})
+	}
+}
@@ -2,4 +2,12 @@ module github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go
 
 go 1.14
 
-require github.com/hyperledger/fabric-contract-api-go v1.1.0
+require (
+	github.com/golang/protobuf v1.3.2
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20210718160520-38d29fabecb9 // indirect
+	github.com/hyperledger/fabric-contract-api-go v1.1.1
+	github.com/hyperledger/fabric-samples/chaincode/mocks v0.0.0
+	github.com/stretchr/testify v1.5.1
+)
+
+replace github.com/hyperledger/fabric-samples/chaincode/mocks => ../../chaincode/mocks
@@ -0,0 +1,408 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define objectType names for prefix
+const policyPrefix = "policy"
+const proposalPrefix = "proposal"
+
+// policyIDPrefix starts the IDs of owner policies, which can not be mistaken for client IDs
+const policyIDPrefix = "policy:"
+
+// OwnerPolicy is a joint custody account. UTXOs owned by the policy ID can only be spent
+// once Threshold of the Signers have approved the transfer.
+type OwnerPolicy struct {
+	ID        string   `json:"id"`
+	Threshold int      `json:"threshold"`
+	Signers   []string `json:"signers"`
+}
+
+// TransferProposal is a transfer spending UTXOs owned by owner policies, and the signers who approved it
+type TransferProposal struct {
+	ID            string   `json:"id"`
+	UTXOInputKeys []string `json:"utxo_input_keys"`
+	UTXOOutputs   []UTXO   `json:"utxo_outputs"`
+	Approvals     []string `json:"approvals"`
+}
+
+// CreatePolicy creates the owner policy requiring threshold approvals among the signers, and returns it.
+// The policy ID is derived from the threshold and the signers, so creating the same policy twice
+// returns the existing policy. UTXOs are sent to the policy by using its ID as the output owner.
+func (s *SmartContract) CreatePolicy(ctx contractapi.TransactionContextInterface, threshold int, signers []string) (*OwnerPolicy, error) {
+
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("a policy requires at least one signer")
+	}
+	if threshold < 1 || threshold > len(signers) {
+		return nil, fmt.Errorf("threshold must be between 1 and the number of signers %d", len(signers))
+	}
+
+	sortedSigners := make([]string, len(signers))
+	copy(sortedSigners, signers)
+	sort.Strings(sortedSigners)
+	for i, signer := range sortedSigners {
+		if signer == "" {
+			return nil, fmt.Errorf("signer must not be empty")
+		}
+		if isPolicyID(signer) {
+			return nil, fmt.Errorf("signer %s must be a client ID, not a policy", signer)
+		}
+		if i > 0 && signer == sortedSigners[i-1] {
+			return nil, fmt.Errorf("signer %s is listed twice", signer)
+		}
+	}
+
+	policy := &OwnerPolicy{Threshold: threshold, Signers: sortedSigners}
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	hash := sha256.Sum256(policyJSON)
+	policy.ID = policyIDPrefix + hex.EncodeToString(hash[:])
+
+	existing, err := readPolicy(ctx, policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	policyKey, err := ctx.GetStub().CreateCompositeKey(policyPrefix, []string{policy.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+	policyJSON, err = json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(policyKey, policyJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("policy created: %+v", policy)
+
+	return policy, nil
+}
+
+// GetPolicy returns the owner policy with the given ID
+func (s *SmartContract) GetPolicy(ctx contractapi.TransactionContextInterface, policyID string) (*OwnerPolicy, error) {
+
+	policy, err := readPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("policy %s does not exist", policyID)
+	}
+
+	return policy, nil
+}
+
+// PolicyUTXOs returns all UTXOs owned by the owner policy
+func (s *SmartContract) PolicyUTXOs(ctx contractapi.TransactionContextInterface, policyID string) ([]*UTXO, error) {
+
+	policy, err := s.GetPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// policy utxos have a composite key of policyID:utxoKey, like the utxos of a client
+	return clientUTXOs(ctx, policy.ID)
+}
+
+// ProposeTransfer records a transfer spending UTXOs owned by owner policies, approved by the calling
+// client, who must be a signer of one of the policies. The other signers approve it with ApproveTransfer,
+// and once every policy reaches its threshold, any of its signers can execute it by calling Transfer with
+// the same inputs and outputs. The proposal ID is derived from the inputs and outputs.
+func (s *SmartContract) ProposeTransfer(ctx contractapi.TransactionContextInterface, utxoInputKeys []string, utxoOutputs []UTXO) (*TransferProposal, error) {
+
+	// Get ID of submitting client identity
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	// Validate that the inputs can be spent by the client, and are owned by at least one policy
+	utxoInputs, err := readUTXOInputs(ctx, clientID, utxoInputKeys)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := inputPolicies(ctx, utxoInputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("no utxo input is owned by a policy, call Transfer instead")
+	}
+
+	// Validate that the outputs hold the same total amount as the inputs
+	err = checkAmountsBalance(utxoInputs, utxoOutputs)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal, err := newTransferProposal(utxoInputKeys, utxoOutputs)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := readProposal(ctx, proposal.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("proposal %s already exists", proposal.ID)
+	}
+
+	proposal.Approvals = []string{clientID}
+	err = putProposal(ctx, proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("transfer proposed: %+v", proposal)
+
+	return proposal, nil
+}
+
+// ApproveTransfer records the approval of the proposal by the calling client, who must be
+// a signer of a policy owning one of its inputs
+func (s *SmartContract) ApproveTransfer(ctx contractapi.TransactionContextInterface, proposalID string) (*TransferProposal, error) {
+
+	// Get ID of submitting client identity
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	proposal, err := s.GetProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range proposal.Approvals {
+		if approval == clientID {
+			return nil, fmt.Errorf("client %s already approved proposal %s", clientID, proposalID)
+		}
+	}
+
+	// Validate that the client is a signer of a policy owning one of the inputs
+	signer := false
+	for _, utxoInputKey := range proposal.UTXOInputKeys {
+		owner, err := utxoOwner(ctx, utxoInputKey)
+		if err != nil {
+			return nil, err
+		}
+		if !isPolicyID(owner) {
+			continue
+		}
+		policy, err := readPolicy(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil && policy.hasSigner(clientID) {
+			signer = true
+			break
+		}
+	}
+	if !signer {
+		return nil, fmt.Errorf("client %s is not a signer of the policies owning the inputs of proposal %s", clientID, proposalID)
+	}
+
+	proposal.Approvals = append(proposal.Approvals, clientID)
+	err = putProposal(ctx, proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("transfer approved by %s: %+v", clientID, proposal)
+
+	return proposal, nil
+}
+
+// GetProposal returns the transfer proposal with the given ID
+func (s *SmartContract) GetProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*TransferProposal, error) {
+
+	proposal, err := readProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, fmt.Errorf("proposal %s does not exist", proposalID)
+	}
+
+	return proposal, nil
+}
+
+// Helper Functions
+
+// hasSigner returns true when the client is a signer of the policy
+func (p *OwnerPolicy) hasSigner(clientID string) bool {
+	for _, signer := range p.Signers {
+		if signer == clientID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPolicyID returns true when the owner of a utxo is an owner policy rather than a client
+func isPolicyID(owner string) bool {
+	return strings.HasPrefix(owner, policyIDPrefix)
+}
+
+// readPolicy returns the owner policy with the given ID, or nil if it does not exist
+func readPolicy(ctx contractapi.TransactionContextInterface, policyID string) (*OwnerPolicy, error) {
+	policyKey, err := ctx.GetStub().CreateCompositeKey(policyPrefix, []string{policyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+	policyJSON, err := ctx.GetStub().GetState(policyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy %s from world state: %v", policyID, err)
+	}
+	if policyJSON == nil {
+		return nil, nil
+	}
+
+	var policy OwnerPolicy
+	err = json.Unmarshal(policyJSON, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policy %s: %v", policyID, err)
+	}
+
+	return &policy, nil
+}
+
+// inputPolicies returns the policies owning the utxo inputs, in the order of their first input
+func inputPolicies(ctx contractapi.TransactionContextInterface, utxoInputs []*UTXO) ([]*OwnerPolicy, error) {
+	var policies []*OwnerPolicy
+	seen := make(map[string]bool)
+	for _, utxoInput := range utxoInputs {
+		if !isPolicyID(utxoInput.Owner) || seen[utxoInput.Owner] {
+			continue
+		}
+		seen[utxoInput.Owner] = true
+
+		policy, err := readPolicy(ctx, utxoInput.Owner)
+		if err != nil {
+			return nil, err
+		}
+		if policy == nil {
+			return nil, fmt.Errorf("policy %s owning utxo %s does not exist", utxoInput.Owner, utxoInput.Key)
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// checkPolicyApprovals validates that a transfer spending utxos owned by policies was proposed,
+// and approved by the threshold of signers of each policy, then deletes the executed proposal
+func checkPolicyApprovals(ctx contractapi.TransactionContextInterface, utxoInputs []*UTXO, utxoInputKeys []string, utxoOutputs []UTXO) error {
+	policies, err := inputPolicies(ctx, utxoInputs)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	proposal, err := newTransferProposal(utxoInputKeys, utxoOutputs)
+	if err != nil {
+		return err
+	}
+	storedProposal, err := readProposal(ctx, proposal.ID)
+	if err != nil {
+		return err
+	}
+	if storedProposal == nil {
+		return fmt.Errorf("utxo inputs owned by a policy require an approved proposal, call ProposeTransfer first")
+	}
+
+	for _, policy := range policies {
+		approvals := 0
+		for _, approval := range storedProposal.Approvals {
+			if policy.hasSigner(approval) {
+				approvals++
+			}
+		}
+		if approvals < policy.Threshold {
+			return fmt.Errorf("proposal %s has %d of the %d approvals required by policy %s", proposal.ID, approvals, policy.Threshold, policy.ID)
+		}
+	}
+
+	proposalKey, err := ctx.GetStub().CreateCompositeKey(proposalPrefix, []string{proposal.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return ctx.GetStub().DelState(proposalKey)
+}
+
+// newTransferProposal returns a proposal without approvals for the transfer. The keys of the outputs
+// are left empty since they are only assigned when the transfer is executed.
+func newTransferProposal(utxoInputKeys []string, utxoOutputs []UTXO) (*TransferProposal, error) {
+	proposal := &TransferProposal{UTXOInputKeys: utxoInputKeys}
+	for _, utxoOutput := range utxoOutputs {
+		proposal.UTXOOutputs = append(proposal.UTXOOutputs, UTXO{Owner: utxoOutput.Owner, Amount: utxoOutput.Amount})
+	}
+
+	transferJSON, err := json.Marshal(proposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	hash := sha256.Sum256(transferJSON)
+	proposal.ID = hex.EncodeToString(hash[:])
+	proposal.Approvals = []string{}
+
+	return proposal, nil
+}
+
+// readProposal returns the transfer proposal with the given ID, or nil if it does not exist
+func readProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*TransferProposal, error) {
+	proposalKey, err := ctx.GetStub().CreateCompositeKey(proposalPrefix, []string{proposalID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+	proposalJSON, err := ctx.GetStub().GetState(proposalKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proposal %s from world state: %v", proposalID, err)
+	}
+	if proposalJSON == nil {
+		return nil, nil
+	}
+
+	var proposal TransferProposal
+	err = json.Unmarshal(proposalJSON, &proposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal %s: %v", proposalID, err)
+	}
+
+	return &proposal, nil
+}
+
+// putProposal stores the transfer proposal
+func putProposal(ctx contractapi.TransactionContextInterface, proposal *TransferProposal) error {
+	proposalKey, err := ctx.GetStub().CreateCompositeKey(proposalPrefix, []string{proposal.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	proposalJSON, err := json.Marshal(proposal)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	return ctx.GetStub().PutState(proposalKey, proposalJSON)
+}
@@ -0,0 +1,211 @@
+package chaincode_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-utxo/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// newPolicyToken returns a contract over a world state in which the minter sent its 100 tokens
+// to a policy requiring 2 approvals among alice, bob and carol, as the UTXO tx2.0
+func newPolicyToken(t *testing.T) (*chaincode.SmartContract, *mocks.WorldState, *chaincode.OwnerPolicy) {
+	token, worldState := newToken(t)
+
+	policy, err := token.CreatePolicy(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), 2, []string{carolID, aliceID, bobID})
+	require.NoError(t, worldState.Submit(err))
+
+	worldState.StartTransaction("tx2", txTime)
+	_, err = token.Transfer(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), []string{"tx1.0"}, []chaincode.UTXO{{Owner: policy.ID, Amount: 100}})
+	require.NoError(t, worldState.Submit(err))
+
+	return token, worldState, policy
+}
+
+func TestCreatePolicy(t *testing.T) {
+	token, worldState, policy := newPolicyToken(t)
+	transactionContext := mocks.NewTransactionContext(worldState, carolID, "Org2MSP")
+
+	require.Equal(t, 2, policy.Threshold)
+	require.Equal(t, []string{aliceID, bobID, carolID}, policy.Signers)
+
+	// The policy ID is derived from the threshold and the signers, whatever their order
+	samePolicy, err := token.CreatePolicy(transactionContext, 2, []string{bobID, carolID, aliceID})
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, policy, samePolicy)
+
+	utxos, err := token.PolicyUTXOs(transactionContext, policy.ID)
+	require.NoError(t, err)
+	require.Equal(t, []*chaincode.UTXO{{Key: "tx2.0", Owner: policy.ID, Amount: 100}}, utxos)
+
+	tests := []struct {
+		threshold int
+		signers   []string
+		wantErr   string
+	}{
+		{1, nil, "a policy requires at least one signer"},
+		{0, []string{aliceID}, "threshold must be between 1 and the number of signers 1"},
+		{3, []string{aliceID, bobID}, "threshold must be between 1 and the number of signers 2"},
+		{1, []string{aliceID, ""}, "signer must not be empty"},
+		{1, []string{aliceID, policy.ID}, fmt.Sprintf("signer %s must be a client ID, not a policy", policy.ID)},
+		{1, []string{aliceID, bobID, aliceID}, "signer alice is listed twice"},
+	}
+	for _, tt := range tests {
+		_, err := token.CreatePolicy(transactionContext, tt.threshold, tt.signers)
+		require.EqualError(t, worldState.Submit(err), tt.wantErr)
+	}
+}
+
+func TestMultisigTransfer(t *testing.T) {
+	tests := []struct {
+		name           string
+		approvers      []string
+		wantApproveErr string
+		executor       string
+		wantErr        string
+	}{
+		{
+			name:      "threshold reached",
+			approvers: []string{bobID},
+			executor:  carolID,
+		},
+		{
+			name:      "threshold exceeded",
+			approvers: []string{bobID, carolID},
+			executor:  aliceID,
+		},
+		{
+			name:     "below threshold",
+			executor: aliceID,
+			wantErr:  "proposal {proposal} has 1 of the 2 approvals required by policy {policy}",
+		},
+		{
+			name:           "double approval",
+			approvers:      []string{aliceID},
+			wantApproveErr: "client alice already approved proposal {proposal}",
+			executor:       aliceID,
+			wantErr:        "proposal {proposal} has 1 of the 2 approvals required by policy {policy}",
+		},
+		{
+			name:           "approval by a non-signer",
+			approvers:      []string{"mallory"},
+			wantApproveErr: "client mallory is not a signer of the policies owning the inputs of proposal {proposal}",
+			executor:       aliceID,
+			wantErr:        "proposal {proposal} has 1 of the 2 approvals required by policy {policy}",
+		},
+		{
+			name:      "execution by a non-signer",
+			approvers: []string{bobID},
+			executor:  "mallory",
+			wantErr:   "utxoInput tx2.0 not found for client mallory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState, policy := newPolicyToken(t)
+			utxoOutputs := []chaincode.UTXO{{Owner: "dave", Amount: 60}, {Owner: policy.ID, Amount: 40}}
+
+			proposal, err := token.ProposeTransfer(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), []string{"tx2.0"}, utxoOutputs)
+			require.NoError(t, worldState.Submit(err))
+			require.Equal(t, []string{aliceID}, proposal.Approvals)
+			ids := strings.NewReplacer("{proposal}", proposal.ID, "{policy}", policy.ID)
+
+			for _, approver := range tt.approvers {
+				_, err = token.ApproveTransfer(mocks.NewTransactionContext(worldState, approver, "Org2MSP"), proposal.ID)
+				err = worldState.Submit(err)
+			}
+			if tt.wantApproveErr != "" {
+				require.EqualError(t, err, ids.Replace(tt.wantApproveErr))
+			} else {
+				require.NoError(t, err)
+			}
+
+			worldState.StartTransaction("tx3", txTime)
+			_, err = token.Transfer(mocks.NewTransactionContext(worldState, tt.executor, "Org2MSP"), []string{"tx2.0"}, utxoOutputs)
+			if tt.wantErr != "" {
+				require.EqualError(t, worldState.Submit(err), ids.Replace(tt.wantErr))
+				requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{
+					policy.ID: {{Key: "tx2.0", Owner: policy.ID, Amount: 100}},
+				})
+				return
+			}
+			require.NoError(t, worldState.Submit(err))
+			requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{
+				"dave":    {{Key: "tx3.0", Owner: "dave", Amount: 60}},
+				policy.ID: {{Key: "tx3.1", Owner: policy.ID, Amount: 40}},
+			})
+
+			// The executed proposal is deleted
+			_, err = token.GetProposal(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), proposal.ID)
+			require.EqualError(t, err, fmt.Sprintf("proposal %s does not exist", proposal.ID))
+		})
+	}
+}
+
+func TestMultisigReexecution(t *testing.T) {
+	token, worldState, policy := newPolicyToken(t)
+	aliceContext := mocks.NewTransactionContext(worldState, aliceID, "Org2MSP")
+	utxoOutputs := []chaincode.UTXO{{Owner: "dave", Amount: 100}}
+
+	proposal, err := token.ProposeTransfer(aliceContext, []string{"tx2.0"}, utxoOutputs)
+	require.NoError(t, worldState.Submit(err))
+	_, err = token.ApproveTransfer(mocks.NewTransactionContext(worldState, bobID, "Org2MSP"), proposal.ID)
+	require.NoError(t, worldState.Submit(err))
+
+	worldState.StartTransaction("tx3", txTime)
+	_, err = token.Transfer(aliceContext, []string{"tx2.0"}, utxoOutputs)
+	require.NoError(t, worldState.Submit(err))
+
+	worldState.StartTransaction("tx4", txTime)
+	_, err = token.Transfer(aliceContext, []string{"tx2.0"}, utxoOutputs)
+	require.EqualError(t, worldState.Submit(err), "utxoInput tx2.0 not found for client alice")
+
+	// Neither can the executed transfer be proposed and approved again
+	_, err = token.ProposeTransfer(aliceContext, []string{"tx2.0"}, utxoOutputs)
+	require.EqualError(t, worldState.Submit(err), "utxoInput tx2.0 not found for client alice")
+	_, err = token.ApproveTransfer(mocks.NewTransactionContext(worldState, carolID, "Org2MSP"), proposal.ID)
+	require.EqualError(t, worldState.Submit(err), fmt.Sprintf("proposal %s does not exist", proposal.ID))
+
+	requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{
+		"dave":    {{Key: "tx3.0", Owner: "dave", Amount: 100}},
+		policy.ID: nil,
+	})
+}
+
+func TestMultisigInputsSpentBeforeExecution(t *testing.T) {
+	token, worldState, policy := newPolicyToken(t)
+	aliceContext := mocks.NewTransactionContext(worldState, aliceID, "Org2MSP")
+	bobContext := mocks.NewTransactionContext(worldState, bobID, "Org2MSP")
+	toDave := []chaincode.UTXO{{Owner: "dave", Amount: 100}}
+	toErin := []chaincode.UTXO{{Owner: "erin", Amount: 100}}
+
+	// Two proposals spend the same input, and both reach the threshold
+	daveProposal, err := token.ProposeTransfer(aliceContext, []string{"tx2.0"}, toDave)
+	require.NoError(t, worldState.Submit(err))
+	erinProposal, err := token.ProposeTransfer(bobContext, []string{"tx2.0"}, toErin)
+	require.NoError(t, worldState.Submit(err))
+	_, err = token.ApproveTransfer(bobContext, daveProposal.ID)
+	require.NoError(t, worldState.Submit(err))
+	_, err = token.ApproveTransfer(aliceContext, erinProposal.ID)
+	require.NoError(t, worldState.Submit(err))
+
+	worldState.StartTransaction("tx3", txTime)
+	_, err = token.Transfer(bobContext, []string{"tx2.0"}, toErin)
+	require.NoError(t, worldState.Submit(err))
+
+	// The other proposal can no longer be approved nor executed
+	_, err = token.ApproveTransfer(mocks.NewTransactionContext(worldState, carolID, "Org2MSP"), daveProposal.ID)
+	require.EqualError(t, worldState.Submit(err), fmt.Sprintf("client carol is not a signer of the policies owning the inputs of proposal %s", daveProposal.ID))
+	worldState.StartTransaction("tx4", txTime)
+	_, err = token.Transfer(aliceContext, []string{"tx2.0"}, toDave)
+	require.EqualError(t, worldState.Submit(err), "utxoInput tx2.0 not found for client alice")
+
+	requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{
+		"dave":    nil,
+		"erin":    {{Key: "tx3.0", Owner: "erin", Amount: 100}},
+		policy.ID: nil,
+	})
+}
@@ -0,0 +1,133 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Define key names for the supply counter
+const totalSupplyKey = "totalSupply"
+
+// maxAmount is the largest amount an int can hold
+const maxAmount = int(^uint(0) >> 1)
+
+// SupplyAudit is a page of the audit of the token supply. Summing PageAmount over every page, until
+// the page returned with an empty bookmark, gives the amount held in live UTXOs, which must equal
+// the RecordedSupply minted minus burned. The pages must be read while no transaction changes
+// the UTXOs for the sum to be consistent.
+type SupplyAudit struct {
+	RecordedSupply      int    `json:"recorded_supply"`
+	PageAmount          int    `json:"page_amount"`
+	FetchedRecordsCount int32  `json:"fetched_records_count"`
+	Bookmark            string `json:"bookmark"`
+}
+
+// TotalSupply returns the amount of tokens minted minus the amount of tokens burned
+func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (int, error) {
+	return readSupply(ctx)
+}
+
+// AuditSupply counts the amount held in a page of pageSize UTXOs, starting at the bookmark returned
+// with the previous page, or at the first UTXO when bookmark is empty, and reports it along with
+// the recorded supply
+func (s *SmartContract) AuditSupply(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*SupplyAudit, error) {
+
+	// Check auditor authorization - this sample assumes Org1 is the central banker with privilege to audit the supply
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != "Org1MSP" {
+		return nil, fmt.Errorf("client is not authorized to audit the supply")
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	recordedSupply, err := readSupply(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the utxos of every owner share the utxo prefix of their composite keys
+	utxoResultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(utxoPrefix, []string{}, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer utxoResultsIterator.Close()
+
+	var pageAmount int
+	for utxoResultsIterator.HasNext() {
+		utxoRecord, err := utxoResultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		amount, err := parseUTXOAmount(utxoRecord.Key, utxoRecord.Value)
+		if err != nil {
+			return nil, err
+		}
+		pageAmount, err = addAmount(pageAmount, amount)
+		if err != nil {
+			return nil, fmt.Errorf("page amount overflows: %v", err)
+		}
+	}
+
+	return &SupplyAudit{
+		RecordedSupply:      recordedSupply,
+		PageAmount:          pageAmount,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}, nil
+}
+
+// Helper Functions
+
+// readSupply returns the recorded supply, which is 0 until tokens are minted or migrated
+func readSupply(ctx contractapi.TransactionContextInterface) (int, error) {
+	supplyBytes, err := ctx.GetStub().GetState(totalSupplyKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read total supply from world state: %v", err)
+	}
+	if supplyBytes == nil {
+		return 0, nil
+	}
+
+	supply, err := strconv.Atoi(string(supplyBytes))
+	if err != nil {
+		return 0, fmt.Errorf("total supply has an invalid amount: %v", err)
+	}
+
+	return supply, nil
+}
+
+// updateSupply adds delta, positive when minting and negative when burning, to the recorded supply
+func updateSupply(ctx contractapi.TransactionContextInterface, delta int) error {
+	supply, err := readSupply(ctx)
+	if err != nil {
+		return err
+	}
+	if delta > 0 {
+		_, err = addAmount(supply, delta)
+		if err != nil {
+			return fmt.Errorf("total supply overflows: %v", err)
+		}
+	}
+	if supply+delta < 0 {
+		return fmt.Errorf("total supply %d can not be reduced by %d", supply, -delta)
+	}
+
+	return ctx.GetStub().PutState(totalSupplyKey, []byte(strconv.Itoa(supply+delta)))
+}
+
+// addAmount returns the sum of the non-negative amounts total and amount, or an error if it overflows
+func addAmount(total int, amount int) (int, error) {
+	if amount > maxAmount-total {
+		return 0, fmt.Errorf("%d + %d exceeds the maximum amount %d", total, amount, maxAmount)
+	}
+
+	return total + amount, nil
+}
@@ -0,0 +1,147 @@
+package chaincode_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-utxo/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// maxAmount is the largest amount an int can hold
+const maxAmount = int(^uint(0) >> 1)
+
+func TestTotalSupply(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	worldState.StartTransaction("tx2", txTime)
+	_, err := token.Mint(minterContext, 50)
+	require.NoError(t, worldState.Submit(err))
+	worldState.StartTransaction("tx3", txTime)
+	_, err = token.TransferAmount(minterContext, aliceID, 30)
+	require.NoError(t, worldState.Submit(err))
+	_, err = token.Burn(minterContext, []string{"tx2.0"})
+	require.NoError(t, worldState.Submit(err))
+
+	supply, err := token.TotalSupply(minterContext)
+	require.NoError(t, err)
+	require.Equal(t, 100, supply)
+
+	// A mint overflowing the supply is rejected, and leaves no utxo behind
+	worldState.StartTransaction("tx4", txTime)
+	_, err = token.Mint(minterContext, maxAmount)
+	require.EqualError(t, worldState.Submit(err), fmt.Sprintf("total supply overflows: 100 + %d exceeds the maximum amount %d", maxAmount, maxAmount))
+	supply, err = token.TotalSupply(minterContext)
+	require.NoError(t, err)
+	require.Equal(t, 100, supply)
+	requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{
+		minterID: {{Key: "tx3.1", Owner: minterID, Amount: 70}},
+	})
+}
+
+func TestAuditSupply(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	worldState.StartTransaction("tx2", txTime)
+	_, err := token.Mint(minterContext, 50)
+	require.NoError(t, worldState.Submit(err))
+	worldState.StartTransaction("tx3", txTime)
+	_, err = token.TransferAmount(minterContext, aliceID, 30)
+	require.NoError(t, worldState.Submit(err))
+
+	audit, err := token.AuditSupply(minterContext, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SupplyAudit{
+		RecordedSupply:      150,
+		PageAmount:          80,
+		FetchedRecordsCount: 2,
+		Bookmark:            "\x00utxo\x00minter\x00tx3.1\x00",
+	}, audit)
+
+	audit, err = token.AuditSupply(minterContext, 2, audit.Bookmark)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SupplyAudit{RecordedSupply: 150, PageAmount: 70, FetchedRecordsCount: 1}, audit)
+
+	// A utxo written outside of Mint and Transfer shows up as drift from the recorded supply.
+	// The audits above are queries, which end without being committed.
+	worldState.Rollback()
+	require.NoError(t, worldState.PutState("\x00utxo\x00mallory\x00tx9.0\x00", []byte("5")))
+	worldState.Commit()
+	audit, err = token.AuditSupply(minterContext, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.SupplyAudit{RecordedSupply: 150, PageAmount: 155, FetchedRecordsCount: 4}, audit)
+
+	_, err = token.AuditSupply(mocks.NewTransactionContext(worldState, aliceID, "Org2MSP"), 10, "")
+	require.EqualError(t, err, "client is not authorized to audit the supply")
+	_, err = token.AuditSupply(minterContext, 0, "")
+	require.EqualError(t, err, "page size must be a positive integer")
+}
+
+func TestTransferAmountsBalance(t *testing.T) {
+	tests := []struct {
+		name        string
+		utxoOutputs []chaincode.UTXO
+		wantErr     string
+	}{
+		{
+			name:        "outputs matching the inputs",
+			utxoOutputs: []chaincode.UTXO{{Owner: aliceID, Amount: 60}, {Owner: bobID, Amount: 40}},
+		},
+		{
+			name:        "outputs exceeding the inputs",
+			utxoOutputs: []chaincode.UTXO{{Owner: aliceID, Amount: 60}, {Owner: bobID, Amount: 41}},
+			wantErr:     "total utxoInput amount 100 does not equal total utxoOutput amount 101",
+		},
+		{
+			name:        "zero output",
+			utxoOutputs: []chaincode.UTXO{{Owner: aliceID, Amount: 100}, {Owner: bobID, Amount: 0}},
+			wantErr:     "utxo output amount must be a positive integer",
+		},
+		{
+			name:        "negative output",
+			utxoOutputs: []chaincode.UTXO{{Owner: aliceID, Amount: 150}, {Owner: bobID, Amount: -50}},
+			wantErr:     "utxo output amount must be a positive integer",
+		},
+		{
+			name:        "outputs wrapping around to the inputs",
+			utxoOutputs: []chaincode.UTXO{{Owner: aliceID, Amount: maxAmount}, {Owner: bobID, Amount: maxAmount}, {Owner: carolID, Amount: 102}},
+			wantErr:     fmt.Sprintf("total utxoOutput amount overflows: %d + %d exceeds the maximum amount %d", maxAmount, maxAmount, maxAmount),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+
+			worldState.StartTransaction("tx2", txTime)
+			_, err := token.Transfer(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), []string{"tx1.0"}, tt.utxoOutputs)
+			if tt.wantErr != "" {
+				require.EqualError(t, worldState.Submit(err), tt.wantErr)
+				requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{
+					minterID: {{Key: "tx1.0", Owner: minterID, Amount: 100}},
+				})
+			} else {
+				require.NoError(t, worldState.Submit(err))
+			}
+
+			audit, err := token.AuditSupply(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), 10, "")
+			require.NoError(t, err)
+			require.Equal(t, 100, audit.RecordedSupply)
+			require.Equal(t, 100, audit.PageAmount)
+		})
+	}
+}
+
+func TestProposeTransferAmountsBalance(t *testing.T) {
+	token, worldState, policy := newPolicyToken(t)
+	aliceContext := mocks.NewTransactionContext(worldState, aliceID, "Org2MSP")
+
+	_, err := token.ProposeTransfer(aliceContext, []string{"tx2.0"}, []chaincode.UTXO{{Owner: aliceID, Amount: 101}, {Owner: policy.ID, Amount: -1}})
+	require.EqualError(t, worldState.Submit(err), "utxo output amount must be a positive integer")
+	_, err = token.ProposeTransfer(aliceContext, []string{"tx2.0"}, []chaincode.UTXO{{Owner: aliceID, Amount: maxAmount}, {Owner: bobID, Amount: maxAmount}, {Owner: carolID, Amount: 102}})
+	require.EqualError(t, worldState.Submit(err), fmt.Sprintf("total utxoOutput amount overflows: %d + %d exceeds the maximum amount %d", maxAmount, maxAmount, maxAmount))
+	_, err = token.ProposeTransfer(aliceContext, []string{"tx2.0"}, []chaincode.UTXO{{Owner: aliceID, Amount: 99}})
+	require.EqualError(t, worldState.Submit(err), "total utxoInput amount 100 does not equal total utxoOutput amount 99")
+}
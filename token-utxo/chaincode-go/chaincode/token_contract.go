@@ -8,6 +8,10 @@ import (
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
+// Define objectType names for prefix
+const utxoPrefix = "utxo"
+const utxoIndexPrefix = "utxoIndex"
+
 // SmartContract provides functions for transferring tokens using UTXO transactions
 type SmartContract struct {
 	contractapi.Contract
@@ -47,10 +51,12 @@ func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount
 	utxo.Owner = minter
 	utxo.Amount = amount
 
-	// the utxo has a composite key of owner:utxoKey, this enables ClientUTXOs() function to query for an owner's utxos.
-	utxoCompositeKey, err := ctx.GetStub().CreateCompositeKey("utxo", []string{minter, utxo.Key})
+	err = putUTXO(ctx, &utxo)
+	if err != nil {
+		return nil, err
+	}
 
-	err = ctx.GetStub().PutState(utxoCompositeKey, []byte(strconv.Itoa(amount)))
+	err = updateSupply(ctx, amount)
 	if err != nil {
 		return nil, err
 	}
@@ -69,103 +75,490 @@ func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, ut
 		return nil, fmt.Errorf("failed to get client id: %v", err)
 	}
 
-	// Validate and summarize utxo inputs
-	utxoInputs := make(map[string]*UTXO)
+	return transferHelper(ctx, clientID, utxoInputKeys, utxoOutputs)
+}
+
+// TransferAmount transfers amount tokens from client to recipient, selecting the client's UTXOs to spend.
+// UTXOs are selected in the order of their keys until they cover the amount. The recipient gets a UTXO
+// of amount tokens, and the client gets back the excess of the selected UTXOs as a change UTXO.
+func (s *SmartContract) TransferAmount(ctx contractapi.TransactionContextInterface, recipient string, amount int) ([]UTXO, error) {
+
+	// Get ID of submitting client identity
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	if recipient == "" {
+		return nil, fmt.Errorf("recipient must not be empty")
+	}
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("transfer amount must be a positive integer")
+	}
+
+	utxos, err := clientUTXOs(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select utxo inputs until they cover the transfer amount
+	var utxoInputKeys []string
 	var totalInputAmount int
-	for _, utxoInputKey := range utxoInputKeys {
-		if utxoInputs[utxoInputKey] != nil {
-			return nil, fmt.Errorf("the same utxo input can not be spend twice")
+	for _, utxo := range utxos {
+		if totalInputAmount >= amount {
+			break
 		}
+		utxoInputKeys = append(utxoInputKeys, utxo.Key)
+		totalInputAmount, err = addAmount(totalInputAmount, utxo.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("total utxoInput amount overflows: %v", err)
+		}
+	}
+	if totalInputAmount < amount {
+		return nil, fmt.Errorf("client %s has insufficient funds: %d tokens available for a transfer of %d", clientID, totalInputAmount, amount)
+	}
+
+	utxoOutputs := []UTXO{{Owner: recipient, Amount: amount}}
+	if change := totalInputAmount - amount; change > 0 {
+		utxoOutputs = append(utxoOutputs, UTXO{Owner: clientID, Amount: change})
+	}
+
+	return transferHelper(ctx, clientID, utxoInputKeys, utxoOutputs)
+}
+
+// Burn redeems UTXOs owned by the minter, destroying the tokens they contain
+func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, utxoKeys []string) ([]*UTXO, error) {
 
-		utxoInputCompositeKey, err := ctx.GetStub().CreateCompositeKey("utxo", []string{clientID, utxoInputKey})
+	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to burn tokens
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != "Org1MSP" {
+		return nil, fmt.Errorf("client is not authorized to burn tokens")
+	}
+
+	// Get ID of submitting client identity
+	minter, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	if len(utxoKeys) == 0 {
+		return nil, fmt.Errorf("at least one utxo must be burned")
+	}
+
+	utxos, err := readUTXOInputs(ctx, minter, utxoKeys)
+	if err != nil {
+		return nil, err
+	}
+	for _, utxo := range utxos {
+		if utxo.Owner != minter {
+			return nil, fmt.Errorf("utxo %s is owned by policy %s and can not be burned", utxo.Key, utxo.Owner)
+		}
+	}
+
+	var burnedAmount int
+	for _, utxo := range utxos {
+		err = deleteUTXO(ctx, utxo)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create composite key: %v", err)
+			return nil, err
 		}
+		burnedAmount, err = addAmount(burnedAmount, utxo.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("burned amount overflows: %v", err)
+		}
+		log.Printf("utxo burned: %+v", utxo)
+	}
 
-		// validate that client has a utxo matching the input key
-		valueBytes, err := ctx.GetStub().GetState(utxoInputCompositeKey)
+	err = updateSupply(ctx, -burnedAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return utxos, nil
+}
+
+// GetUTXO returns the unspent transaction output with the given key
+func (s *SmartContract) GetUTXO(ctx contractapi.TransactionContextInterface, utxoKey string) (*UTXO, error) {
+
+	owner, err := utxoOwner(ctx, utxoKey)
+	if err != nil {
+		return nil, err
+	}
+	if owner == "" {
+		return nil, fmt.Errorf("utxo %s does not exist", utxoKey)
+	}
+
+	utxo, err := readUTXO(ctx, owner, utxoKey)
+	if err != nil {
+		return nil, err
+	}
+	if utxo == nil {
+		return nil, fmt.Errorf("utxo %s does not exist", utxoKey)
+	}
+
+	return utxo, nil
+}
+
+// ClientUTXOs returns all UTXOs owned by the calling client
+func (s *SmartContract) ClientUTXOs(ctx contractapi.TransactionContextInterface) ([]*UTXO, error) {
+
+	// Get ID of submitting client identity
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	return clientUTXOs(ctx, clientID)
+}
+
+// ClientID returns the client id of the calling client
+// Users can use this function to get their own client id, which they can then give to others as the payment address
+func (s *SmartContract) ClientID(ctx contractapi.TransactionContextInterface) (string, error) {
+
+	// Get ID of submitting client identity
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	return clientID, nil
+}
+
+// UTXOMigrationResult is the outcome of a page of MigrateUTXOs
+type UTXOMigrationResult struct {
+	MigratedUTXOs       []*UTXO `json:"migrated_utxos"`
+	ScannedRecordsCount int32   `json:"scanned_records_count"`
+	NextStartKey        string  `json:"next_start_key"`
+}
+
+// MigrateUTXOs indexes a page of the UTXOs created by earlier versions of the contract, which did
+// not index the owner of a UTXO under its key. The page is read from pageSize UTXOs, starting at the
+// next start key returned with the previous page, or at the first UTXO when startKey is empty.
+// The UTXOs indexed by the page are returned, and the last page with an empty next start key.
+// Their amounts are added to the recorded supply, which only counts the tokens minted since the
+// upgrade. Until their migration, the earlier UTXOs can not be looked up or spent, so the migration
+// should run right after the upgrade.
+func (s *SmartContract) MigrateUTXOs(ctx contractapi.TransactionContextInterface, pageSize int32, startKey string) (*UTXOMigrationResult, error) {
+
+	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to migrate the UTXOs
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != "Org1MSP" {
+		return nil, fmt.Errorf("client is not authorized to migrate utxos")
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be a positive integer")
+	}
+
+	// paginated queries are not allowed in update transactions, so the page is bounded by hand:
+	// the utxo keys before the start key are skipped
+	utxoResultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(utxoPrefix, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer utxoResultsIterator.Close()
+
+	result := &UTXOMigrationResult{MigratedUTXOs: []*UTXO{}}
+	var migratedAmount int
+	for utxoResultsIterator.HasNext() {
+		utxoRecord, err := utxoResultsIterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to read utxoInputCompositeKey %s from world state: %v", utxoInputCompositeKey, err)
+			return nil, err
+		}
+		if utxoRecord.Key < startKey {
+			continue
 		}
+		if result.ScannedRecordsCount == pageSize {
+			result.NextStartKey = utxoRecord.Key
+			break
+		}
+		result.ScannedRecordsCount++
 
-		if valueBytes == nil {
-			return nil, fmt.Errorf("utxoInput %s not found for client %s", utxoInputKey, clientID)
+		// composite key is expected to be owner:utxoKey
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(utxoRecord.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(compositeKeyParts) != 2 {
+			return nil, fmt.Errorf("expected composite key with two parts (owner:utxoKey)")
 		}
+		owner, utxoKey := compositeKeyParts[0], compositeKeyParts[1]
 
-		amount, _ := strconv.Atoi(string(valueBytes)) // Error handling not needed since Itoa() was used when setting the utxo amount, guaranteeing it was an integer.
+		indexedOwner, err := utxoOwner(ctx, utxoKey)
+		if err != nil {
+			return nil, err
+		}
+		if indexedOwner != "" {
+			continue
+		}
 
-		utxoInput := &UTXO{
-			Key:    utxoInputKey,
-			Owner:  clientID,
-			Amount: amount,
+		amount, err := parseUTXOAmount(utxoKey, utxoRecord.Value)
+		if err != nil {
+			return nil, err
+		}
+		utxo := &UTXO{Key: utxoKey, Owner: owner, Amount: amount}
+		err = putUTXO(ctx, utxo)
+		if err != nil {
+			return nil, err
+		}
+
+		migratedAmount, err = addAmount(migratedAmount, amount)
+		if err != nil {
+			return nil, fmt.Errorf("migrated amount overflows: %v", err)
 		}
 
-		totalInputAmount += amount
-		utxoInputs[utxoInputKey] = utxoInput
+		log.Printf("utxo migrated: %+v", utxo)
+		result.MigratedUTXOs = append(result.MigratedUTXOs, utxo)
 	}
 
-	// Validate and summarize utxo outputs
-	var totalOutputAmount int
+	// the utxos created by this version are indexed and already counted in the supply
+	if migratedAmount > 0 {
+		err = updateSupply(ctx, migratedAmount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Helper Functions
+
+// transferHelper spends the utxo inputs of the client, or of the policies the client is a signer of, and creates the utxo outputs holding the same total amount
+func transferHelper(ctx contractapi.TransactionContextInterface, clientID string, utxoInputKeys []string, utxoOutputs []UTXO) ([]UTXO, error) {
+
+	// Validate and summarize utxo inputs
+	utxoInputs, err := readUTXOInputs(ctx, clientID, utxoInputKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate that the outputs hold the same total amount as the inputs
+	err = checkAmountsBalance(utxoInputs, utxoOutputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate utxo outputs
 	txID := ctx.GetStub().GetTxID()
 	for i, utxoOutput := range utxoOutputs {
 
-		if utxoOutput.Amount <= 0 {
-			return nil, fmt.Errorf("utxo output amount must be a positive integer")
+		// Validate that an output sent to a policy can be spent
+		if isPolicyID(utxoOutput.Owner) {
+			policy, err := readPolicy(ctx, utxoOutput.Owner)
+			if err != nil {
+				return nil, err
+			}
+			if policy == nil {
+				return nil, fmt.Errorf("utxo output owner policy %s does not exist", utxoOutput.Owner)
+			}
 		}
 
 		utxoOutputs[i].Key = fmt.Sprintf("%s.%d", txID, i)
-
-		totalOutputAmount += utxoOutput.Amount
 	}
 
-	// Validate total inputs equals total outputs
-	if totalInputAmount != totalOutputAmount {
-		return nil, fmt.Errorf("total utxoInput amount %d does not equal total utxoOutput amount %d", totalInputAmount, totalOutputAmount)
+	// Validate that the utxo inputs owned by policies were approved by their signers
+	err = checkPolicyApprovals(ctx, utxoInputs, utxoInputKeys, utxoOutputs)
+	if err != nil {
+		return nil, err
 	}
 
 	// Since the transaction is valid, now delete utxo inputs from owner's state
 	for _, utxoInput := range utxoInputs {
-
-		utxoInputCompositeKey, err := ctx.GetStub().CreateCompositeKey("utxo", []string{utxoInput.Owner, utxoInput.Key})
+		err = deleteUTXO(ctx, utxoInput)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create composite key: %v", err)
+			return nil, err
 		}
+		log.Printf("utxoInput deleted: %+v", utxoInput)
+	}
 
-		err = ctx.GetStub().DelState(utxoInputCompositeKey)
+	// Create utxo outputs using a composite key based on the owner and utxo key
+	for i := range utxoOutputs {
+		err = putUTXO(ctx, &utxoOutputs[i])
 		if err != nil {
 			return nil, err
 		}
-		log.Printf("utxoInput deleted: %+v", utxoInput)
+		log.Printf("utxoOutput created: %+v", utxoOutputs[i])
 	}
 
-	// Create utxo outputs using a composite key based on the owner and utxo key
+	return utxoOutputs, nil
+}
+
+// checkAmountsBalance validates that every utxo output amount is positive, and that the utxo outputs hold
+// the same total amount as the utxo inputs. The totals are summed with overflow checks, so that outputs
+// can not wrap around to the input total.
+func checkAmountsBalance(utxoInputs []*UTXO, utxoOutputs []UTXO) error {
+	var totalInputAmount int
+	for _, utxoInput := range utxoInputs {
+		var err error
+		totalInputAmount, err = addAmount(totalInputAmount, utxoInput.Amount)
+		if err != nil {
+			return fmt.Errorf("total utxoInput amount overflows: %v", err)
+		}
+	}
+
+	var totalOutputAmount int
 	for _, utxoOutput := range utxoOutputs {
-		utxoOutputCompositeKey, err := ctx.GetStub().CreateCompositeKey("utxo", []string{utxoOutput.Owner, utxoOutput.Key})
+		if utxoOutput.Amount <= 0 {
+			return fmt.Errorf("utxo output amount must be a positive integer")
+		}
+
+		var err error
+		totalOutputAmount, err = addAmount(totalOutputAmount, utxoOutput.Amount)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create composite key: %v", err)
+			return fmt.Errorf("total utxoOutput amount overflows: %v", err)
 		}
+	}
+
+	if totalInputAmount != totalOutputAmount {
+		return fmt.Errorf("total utxoInput amount %d does not equal total utxoOutput amount %d", totalInputAmount, totalOutputAmount)
+	}
+
+	return nil
+}
 
-		err = ctx.GetStub().PutState(utxoOutputCompositeKey, []byte(strconv.Itoa(utxoOutput.Amount)))
+// readUTXOInputs returns the utxos matching the input keys, in the order of the keys. Each utxo must be owned
+// by the client, or by a policy the client is a signer of.
+func readUTXOInputs(ctx contractapi.TransactionContextInterface, clientID string, utxoInputKeys []string) ([]*UTXO, error) {
+	seen := make(map[string]bool)
+	var utxoInputs []*UTXO
+	for _, utxoInputKey := range utxoInputKeys {
+		if seen[utxoInputKey] {
+			return nil, fmt.Errorf("the same utxo input can not be spend twice")
+		}
+		seen[utxoInputKey] = true
+
+		// validate that client has a utxo matching the input key
+		utxoInput, err := readUTXO(ctx, clientID, utxoInputKey)
 		if err != nil {
 			return nil, err
 		}
-		log.Printf("utxoOutput created: %+v", utxoOutput)
+
+		owner, err := utxoOwner(ctx, utxoInputKey)
+		if err != nil {
+			return nil, err
+		}
+
+		// utxos created by earlier versions of the contract are not indexed until they are migrated
+		if utxoInput != nil && owner == "" {
+			return nil, fmt.Errorf("utxoInput %s was created by an earlier version of the contract, MigrateUTXOs must be run first", utxoInputKey)
+		}
+
+		// otherwise validate that the utxo is owned by a policy the client is a signer of
+		if utxoInput == nil && isPolicyID(owner) {
+			policy, err := readPolicy(ctx, owner)
+			if err != nil {
+				return nil, err
+			}
+			if policy != nil && policy.hasSigner(clientID) {
+				utxoInput, err = readUTXO(ctx, owner, utxoInputKey)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if utxoInput == nil {
+			return nil, fmt.Errorf("utxoInput %s not found for client %s", utxoInputKey, clientID)
+		}
+
+		utxoInputs = append(utxoInputs, utxoInput)
 	}
 
-	return utxoOutputs, nil
+	return utxoInputs, nil
 }
 
-// ClientUTXOs returns all UTXOs owned by the calling client
-func (s *SmartContract) ClientUTXOs(ctx contractapi.TransactionContextInterface) ([]*UTXO, error) {
+// utxoOwner returns the owner of the utxo with the given key, or an empty string if it does not exist
+func utxoOwner(ctx contractapi.TransactionContextInterface, utxoKey string) (string, error) {
+	utxoIndexKey, err := ctx.GetStub().CreateCompositeKey(utxoIndexPrefix, []string{utxoKey})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %v", err)
+	}
 
-	// Get ID of submitting client identity
-	clientID, err := ctx.GetClientIdentity().GetID()
+	// the index maps the utxo key to the owner, which completes the composite key of the utxo
+	ownerBytes, err := ctx.GetStub().GetState(utxoIndexKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get client id: %v", err)
+		return "", fmt.Errorf("failed to read utxo index %s from world state: %v", utxoKey, err)
+	}
+
+	return string(ownerBytes), nil
+}
+
+// readUTXO returns the utxo of the owner with the given key, or nil if the owner has no such utxo
+func readUTXO(ctx contractapi.TransactionContextInterface, owner string, utxoKey string) (*UTXO, error) {
+	utxoCompositeKey, err := ctx.GetStub().CreateCompositeKey(utxoPrefix, []string{owner, utxoKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	valueBytes, err := ctx.GetStub().GetState(utxoCompositeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read utxoCompositeKey %s from world state: %v", utxoCompositeKey, err)
+	}
+	if valueBytes == nil {
+		return nil, nil
+	}
+
+	amount, err := parseUTXOAmount(utxoKey, valueBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UTXO{Key: utxoKey, Owner: owner, Amount: amount}, nil
+}
+
+// putUTXO stores the utxo under a composite key of owner:utxoKey, and indexes its owner under the utxo key
+func putUTXO(ctx contractapi.TransactionContextInterface, utxo *UTXO) error {
+
+	// the utxo has a composite key of owner:utxoKey, this enables ClientUTXOs() function to query for an owner's utxos.
+	utxoCompositeKey, err := ctx.GetStub().CreateCompositeKey(utxoPrefix, []string{utxo.Owner, utxo.Key})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = ctx.GetStub().PutState(utxoCompositeKey, []byte(strconv.Itoa(utxo.Amount)))
+	if err != nil {
+		return err
+	}
+
+	// the index enables GetUTXO() function to find a utxo by its key alone
+	utxoIndexKey, err := ctx.GetStub().CreateCompositeKey(utxoIndexPrefix, []string{utxo.Key})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	return ctx.GetStub().PutState(utxoIndexKey, []byte(utxo.Owner))
+}
+
+// deleteUTXO deletes the utxo and its index entry
+func deleteUTXO(ctx contractapi.TransactionContextInterface, utxo *UTXO) error {
+	utxoCompositeKey, err := ctx.GetStub().CreateCompositeKey(utxoPrefix, []string{utxo.Owner, utxo.Key})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	err = ctx.GetStub().DelState(utxoCompositeKey)
+	if err != nil {
+		return err
+	}
+
+	utxoIndexKey, err := ctx.GetStub().CreateCompositeKey(utxoIndexPrefix, []string{utxo.Key})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
 	}
 
+	return ctx.GetStub().DelState(utxoIndexKey)
+}
+
+// clientUTXOs returns all UTXOs owned by the client, in the order of their keys
+func clientUTXOs(ctx contractapi.TransactionContextInterface, clientID string) ([]*UTXO, error) {
+
 	// since utxos have a composite key of owner:utxoKey, we can query for all utxos matching owner:*
-	utxoResultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey("utxo", []string{clientID})
+	utxoResultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(utxoPrefix, []string{clientID})
 	if err != nil {
 		return nil, err
 	}
@@ -194,7 +587,10 @@ func (s *SmartContract) ClientUTXOs(ctx contractapi.TransactionContextInterface)
 			return nil, fmt.Errorf("utxo %s has no value", utxoKey)
 		}
 
-		amount, _ := strconv.Atoi(string(utxoRecord.Value)) // Error handling not needed since Itoa() was used when setting the utxo amount, guaranteeing it was an integer.
+		amount, err := parseUTXOAmount(utxoKey, utxoRecord.Value)
+		if err != nil {
+			return nil, err
+		}
 
 		utxo := &UTXO{
 			Key:    utxoKey,
@@ -207,15 +603,15 @@ func (s *SmartContract) ClientUTXOs(ctx contractapi.TransactionContextInterface)
 	return utxos, nil
 }
 
-// ClientID returns the client id of the calling client
-// Users can use this function to get their own client id, which they can then give to others as the payment address
-func (s *SmartContract) ClientID(ctx contractapi.TransactionContextInterface) (string, error) {
-
-	// Get ID of submitting client identity
-	clientID, err := ctx.GetClientIdentity().GetID()
+// parseUTXOAmount parses the amount stored in a utxo
+func parseUTXOAmount(utxoKey string, valueBytes []byte) (int, error) {
+	amount, err := strconv.Atoi(string(valueBytes))
 	if err != nil {
-		return "", fmt.Errorf("failed to get client id: %v", err)
+		return 0, fmt.Errorf("utxo %s has an invalid amount: %v", utxoKey, err)
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("utxo %s has an invalid amount: %d is not a positive integer", utxoKey, amount)
 	}
 
-	return clientID, nil
+	return amount, nil
 }
@@ -0,0 +1,290 @@
+package chaincode_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/chaincode/mocks"
+	"github.com/hyperledger/fabric-samples/token-utxo/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	minterID = "minter"
+	aliceID  = "alice"
+	bobID    = "bob"
+	carolID  = "carol"
+)
+
+// txTime is the timestamp of every transaction of the tests
+var txTime = time.Unix(1600000000, 0)
+
+// newToken returns a contract over an in-memory world state, in which the minter minted
+// 100 tokens as the UTXO tx1.0
+func newToken(t *testing.T) (*chaincode.SmartContract, *mocks.WorldState) {
+	worldState := mocks.NewWorldState("tx1", txTime)
+	token := &chaincode.SmartContract{}
+
+	_, err := token.Mint(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), 100)
+	require.NoError(t, worldState.Submit(err))
+
+	return token, worldState
+}
+
+// requireUTXOs checks the UTXOs owned by each owner, in the order of their keys
+func requireUTXOs(t *testing.T, token *chaincode.SmartContract, worldState *mocks.WorldState, utxos map[string][]*chaincode.UTXO) {
+	for owner, expected := range utxos {
+		actual, err := token.ClientUTXOs(mocks.NewTransactionContext(worldState, owner, "Org1MSP"))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, owner)
+	}
+}
+
+func TestTransferAmount(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int
+		wantErr   string
+		wantUTXOs map[string][]*chaincode.UTXO
+	}{
+		{
+			name:   "whole utxo",
+			amount: 100,
+			wantUTXOs: map[string][]*chaincode.UTXO{
+				minterID: {{Key: "tx2.0", Owner: minterID, Amount: 50}},
+				aliceID:  {{Key: "tx3.0", Owner: aliceID, Amount: 100}},
+			},
+		},
+		{
+			name:   "change returned to the client",
+			amount: 30,
+			wantUTXOs: map[string][]*chaincode.UTXO{
+				minterID: {{Key: "tx2.0", Owner: minterID, Amount: 50}, {Key: "tx3.1", Owner: minterID, Amount: 70}},
+				aliceID:  {{Key: "tx3.0", Owner: aliceID, Amount: 30}},
+			},
+		},
+		{
+			name:   "several utxos",
+			amount: 120,
+			wantUTXOs: map[string][]*chaincode.UTXO{
+				minterID: {{Key: "tx3.1", Owner: minterID, Amount: 30}},
+				aliceID:  {{Key: "tx3.0", Owner: aliceID, Amount: 120}},
+			},
+		},
+		{
+			name:   "whole balance",
+			amount: 150,
+			wantUTXOs: map[string][]*chaincode.UTXO{
+				minterID: nil,
+				aliceID:  {{Key: "tx3.0", Owner: aliceID, Amount: 150}},
+			},
+		},
+		{
+			name:    "insufficient funds",
+			amount:  151,
+			wantErr: "client minter has insufficient funds: 150 tokens available for a transfer of 151",
+			wantUTXOs: map[string][]*chaincode.UTXO{
+				minterID: {{Key: "tx1.0", Owner: minterID, Amount: 100}, {Key: "tx2.0", Owner: minterID, Amount: 50}},
+				aliceID:  nil,
+			},
+		},
+		{
+			name:    "zero amount",
+			amount:  0,
+			wantErr: "transfer amount must be a positive integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+			minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+			worldState.StartTransaction("tx2", txTime)
+			_, err := token.Mint(minterContext, 50)
+			require.NoError(t, worldState.Submit(err))
+
+			worldState.StartTransaction("tx3", txTime)
+			_, err = token.TransferAmount(minterContext, aliceID, tt.amount)
+			if tt.wantErr != "" {
+				require.EqualError(t, worldState.Submit(err), tt.wantErr)
+			} else {
+				require.NoError(t, worldState.Submit(err))
+			}
+			requireUTXOs(t, token, worldState, tt.wantUTXOs)
+		})
+	}
+}
+
+func TestBurn(t *testing.T) {
+	tests := []struct {
+		name      string
+		mspID     string
+		utxoKeys  []string
+		wantErr   string
+		wantUTXOs []*chaincode.UTXO
+	}{
+		{
+			name:      "one utxo",
+			mspID:     "Org1MSP",
+			utxoKeys:  []string{"tx1.0"},
+			wantUTXOs: []*chaincode.UTXO{{Key: "tx2.0", Owner: minterID, Amount: 50}},
+		},
+		{
+			name:     "several utxos",
+			mspID:    "Org1MSP",
+			utxoKeys: []string{"tx2.0", "tx1.0"},
+		},
+		{
+			name:      "client without burn authorization",
+			mspID:     "Org2MSP",
+			utxoKeys:  []string{"tx1.0"},
+			wantErr:   "client is not authorized to burn tokens",
+			wantUTXOs: []*chaincode.UTXO{{Key: "tx1.0", Owner: minterID, Amount: 100}, {Key: "tx2.0", Owner: minterID, Amount: 50}},
+		},
+		{
+			name:      "no utxo",
+			mspID:     "Org1MSP",
+			wantErr:   "at least one utxo must be burned",
+			wantUTXOs: []*chaincode.UTXO{{Key: "tx1.0", Owner: minterID, Amount: 100}, {Key: "tx2.0", Owner: minterID, Amount: 50}},
+		},
+		{
+			name:      "unknown utxo",
+			mspID:     "Org1MSP",
+			utxoKeys:  []string{"tx1.0", "tx9.0"},
+			wantErr:   "utxoInput tx9.0 not found for client minter",
+			wantUTXOs: []*chaincode.UTXO{{Key: "tx1.0", Owner: minterID, Amount: 100}, {Key: "tx2.0", Owner: minterID, Amount: 50}},
+		},
+		{
+			name:      "same utxo twice",
+			mspID:     "Org1MSP",
+			utxoKeys:  []string{"tx1.0", "tx1.0"},
+			wantErr:   "the same utxo input can not be spend twice",
+			wantUTXOs: []*chaincode.UTXO{{Key: "tx1.0", Owner: minterID, Amount: 100}, {Key: "tx2.0", Owner: minterID, Amount: 50}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, worldState := newToken(t)
+			worldState.StartTransaction("tx2", txTime)
+			_, err := token.Mint(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), 50)
+			require.NoError(t, worldState.Submit(err))
+
+			burned, err := token.Burn(mocks.NewTransactionContext(worldState, minterID, tt.mspID), tt.utxoKeys)
+			if tt.wantErr != "" {
+				require.EqualError(t, worldState.Submit(err), tt.wantErr)
+			} else {
+				require.NoError(t, worldState.Submit(err))
+				require.Len(t, burned, len(tt.utxoKeys))
+			}
+			requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{minterID: tt.wantUTXOs})
+		})
+	}
+}
+
+func TestBurnPolicyUTXO(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+
+	policy, err := token.CreatePolicy(minterContext, 1, []string{minterID})
+	require.NoError(t, worldState.Submit(err))
+	worldState.StartTransaction("tx2", txTime)
+	_, err = token.Transfer(minterContext, []string{"tx1.0"}, []chaincode.UTXO{{Owner: policy.ID, Amount: 100}})
+	require.NoError(t, worldState.Submit(err))
+
+	_, err = token.Burn(minterContext, []string{"tx2.0"})
+	require.EqualError(t, worldState.Submit(err), "utxo tx2.0 is owned by policy "+policy.ID+" and can not be burned")
+}
+
+func TestGetUTXO(t *testing.T) {
+	token, worldState := newToken(t)
+	transactionContext := mocks.NewTransactionContext(worldState, aliceID, "Org2MSP")
+
+	utxo, err := token.GetUTXO(transactionContext, "tx1.0")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.UTXO{Key: "tx1.0", Owner: minterID, Amount: 100}, utxo)
+
+	worldState.StartTransaction("tx2", txTime)
+	_, err = token.TransferAmount(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), aliceID, 40)
+	require.NoError(t, worldState.Submit(err))
+
+	_, err = token.GetUTXO(transactionContext, "tx1.0")
+	require.EqualError(t, err, "utxo tx1.0 does not exist")
+	utxo, err = token.GetUTXO(transactionContext, "tx2.1")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.UTXO{Key: "tx2.1", Owner: minterID, Amount: 60}, utxo)
+	_, err = token.GetUTXO(transactionContext, "tx9.0")
+	require.EqualError(t, err, "utxo tx9.0 does not exist")
+}
+
+func TestTransferAmountEmptyRecipient(t *testing.T) {
+	token, worldState := newToken(t)
+
+	worldState.StartTransaction("tx2", txTime)
+	_, err := token.TransferAmount(mocks.NewTransactionContext(worldState, minterID, "Org1MSP"), "", 40)
+	require.EqualError(t, worldState.Submit(err), "recipient must not be empty")
+	requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{minterID: {{Key: "tx1.0", Owner: minterID, Amount: 100}}})
+}
+
+func TestMigrateUTXOs(t *testing.T) {
+	token, worldState := newToken(t)
+	minterContext := mocks.NewTransactionContext(worldState, minterID, "Org1MSP")
+	aliceContext := mocks.NewTransactionContext(worldState, aliceID, "Org2MSP")
+
+	// earlier versions of the contract stored the utxos without indexing their owner
+	for _, utxo := range []*chaincode.UTXO{{Key: "old1.0", Owner: aliceID, Amount: 30}, {Key: "old2.0", Owner: minterID, Amount: 20}} {
+		utxoCompositeKey, err := worldState.CreateCompositeKey("utxo", []string{utxo.Owner, utxo.Key})
+		require.NoError(t, err)
+		require.NoError(t, worldState.PutState(utxoCompositeKey, []byte(strconv.Itoa(utxo.Amount))))
+	}
+	worldState.Commit()
+
+	_, err := token.GetUTXO(aliceContext, "old1.0")
+	require.EqualError(t, err, "utxo old1.0 does not exist")
+	worldState.StartTransaction("tx2", txTime)
+	_, err = token.TransferAmount(aliceContext, bobID, 10)
+	require.EqualError(t, worldState.Submit(err), "utxoInput old1.0 was created by an earlier version of the contract, MigrateUTXOs must be run first")
+
+	supply, err := token.TotalSupply(minterContext)
+	require.NoError(t, err)
+	require.Equal(t, 100, supply)
+
+	_, err = token.MigrateUTXOs(aliceContext, 2, "")
+	require.EqualError(t, worldState.Submit(err), "client is not authorized to migrate utxos")
+	_, err = token.MigrateUTXOs(minterContext, 0, "")
+	require.EqualError(t, worldState.Submit(err), "page size must be a positive integer")
+
+	worldState.StartTransaction("tx3", txTime)
+	result, err := token.MigrateUTXOs(minterContext, 2, "")
+	require.NoError(t, worldState.Submit(err))
+	nextStartKey, err := worldState.CreateCompositeKey("utxo", []string{minterID, "tx1.0"})
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.UTXOMigrationResult{
+		MigratedUTXOs:       []*chaincode.UTXO{{Key: "old1.0", Owner: aliceID, Amount: 30}, {Key: "old2.0", Owner: minterID, Amount: 20}},
+		ScannedRecordsCount: 2,
+		NextStartKey:        nextStartKey,
+	}, result)
+
+	supply, err = token.TotalSupply(minterContext)
+	require.NoError(t, err)
+	require.Equal(t, 150, supply)
+
+	// the utxos created by this version are already indexed and counted in the supply
+	worldState.StartTransaction("tx4", txTime)
+	result, err = token.MigrateUTXOs(minterContext, 2, result.NextStartKey)
+	require.NoError(t, worldState.Submit(err))
+	require.Equal(t, &chaincode.UTXOMigrationResult{MigratedUTXOs: []*chaincode.UTXO{}, ScannedRecordsCount: 1}, result)
+	supply, err = token.TotalSupply(minterContext)
+	require.NoError(t, err)
+	require.Equal(t, 150, supply)
+
+	utxo, err := token.GetUTXO(aliceContext, "old1.0")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.UTXO{Key: "old1.0", Owner: aliceID, Amount: 30}, utxo)
+	worldState.StartTransaction("tx5", txTime)
+	_, err = token.TransferAmount(aliceContext, bobID, 10)
+	require.NoError(t, worldState.Submit(err))
+	requireUTXOs(t, token, worldState, map[string][]*chaincode.UTXO{
+		aliceID: {{Key: "tx5.1", Owner: aliceID, Amount: 20}},
+		bobID:   {{Key: "tx5.0", Owner: bobID, Amount: 10}},
+	})
+}